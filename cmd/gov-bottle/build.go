@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"gov-brew-bottle-creation/internal/brew"
+	"gov-brew-bottle-creation/internal/fsutil"
+	"gov-brew-bottle-creation/internal/junit"
+)
+
+// brewBuild: uninstall, install --build-bottle und bottle in workDir; liefert das erzeugte .tar.gz.
+// Der Aufrufer hält den Prefix-Lock.
+func brewBuild(ctx context.Context, jr *junit.Recorder, brewBin, ref, workDir string, env map[string]string) (string, int) {
+	// uninstall (Fehler nur loggen)
+	stepStarted := time.Now()
+	_, stderr, code, err := brew.Run(ctx, brewBin,
+		[]string{"uninstall", "--ignore-dependencies", ref},
+		"", nil,
+	)
+	brewStep("uninstall", stepStarted)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: uninstall failed:", err)
+		_, _ = fmt.Fprintln(os.Stderr, "stderr:", stderr)
+		_, _ = fmt.Fprintln(os.Stderr, "exit:", code)
+	}
+
+	// install --build-bottle
+	stepStarted = time.Now()
+	_, stderr, code, err = brew.Run(ctx, brewBin,
+		[]string{"install", "--build-bottle", ref},
+		workDir, env,
+	)
+	brewStep("install", stepStarted)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: build-bottle failed:", err)
+		_, _ = fmt.Fprintln(os.Stderr, "stderr:", stderr)
+		_, _ = fmt.Fprintln(os.Stderr, "exit:", code)
+		jr.Fail(fmt.Sprintf("brew install --build-bottle failed (exit %d): %v", code, err), junit.Tail(stderr, 50))
+		if h := hint(err); h != "" {
+			_, _ = fmt.Fprintln(os.Stderr, "hint:", h)
+		}
+		return "", exitCode(err)
+	}
+
+	// brew bottle
+	stepStarted = time.Now()
+	_, stderr, code, err = brew.Run(ctx, brewBin,
+		[]string{"bottle", "--no-rebuild", ref},
+		workDir, env,
+	)
+	brewStep("bottle", stepStarted)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: brew bottle failed:", err)
+		_, _ = fmt.Fprintln(os.Stderr, "stderr:", stderr)
+		_, _ = fmt.Fprintln(os.Stderr, "exit:", code)
+		jr.Fail(fmt.Sprintf("brew bottle failed (exit %d): %v", code, err), junit.Tail(stderr, 50))
+		if h := hint(err); h != "" {
+			_, _ = fmt.Fprintln(os.Stderr, "hint:", h)
+		}
+		return "", exitCode(err)
+	}
+
+	produced, err := fsutil.FindBottleTarGz(workDir)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: failed to find bottle:", err)
+		return "", 1
+	}
+	return produced, 0
+}
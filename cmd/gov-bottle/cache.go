@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gov-brew-bottle-creation/internal/brew"
+	"gov-brew-bottle-creation/internal/cache"
+	"gov-brew-bottle-creation/internal/nexus"
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// cacheKey sammelt die Cache-Inputs via brew (Formula-Inhalt, Version/Revision, Dependencies).
+func cacheKey(ctx context.Context, brewBin, ref, tag string) (string, error) {
+	bc := brew.Client{BrewPath: brewBin}
+
+	fi, err := bc.FormulaInfo(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	src := fi.SourceSha256
+	if src == "" {
+		// ältere brew Versionen liefern keine ruby_source_checksum
+		out, stderr, _, err := brew.Run(ctx, brewBin, []string{"cat", ref}, "", nil)
+		if err != nil {
+			return "", fmt.Errorf("brew cat failed: %w (stderr=%q)", err, stderr)
+		}
+		sum := sha256.Sum256([]byte(out))
+		src = hex.EncodeToString(sum[:])
+	}
+
+	deps, err := bc.ResolvedDependencies(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	return cache.Key(cache.Inputs{
+		FormulaSha256: src,
+		Version:       fi.Version,
+		Revision:      fi.Revision,
+		Tag:           tag,
+		Dependencies:  deps,
+	}), nil
+}
+
+// lookupCache sucht zuerst in dist/, dann in Nexus nach einem verifizierten Bottle mit gleichem Key.
+// Bei einem Treffer aus Nexus werden Bottle und Report nach workdir geladen.
+func lookupCache(ctx context.Context, up nexus.Uploader, user, pass, workdir string, planned report.BottleReport, key string) (*report.BottleReport, string) {
+	rep, bottlePath, err := cache.FromDist(workdir, planned.JSONFile, key)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: cache: dist:", err)
+	}
+	if rep != nil {
+		rep.CacheHit = "dist"
+		return rep, bottlePath
+	}
+
+	if planned.NexusURLJSON == "" || planned.NexusURLBottle == "" {
+		return nil, ""
+	}
+	b, err := up.Get(ctx, planned.NexusURLJSON, user, pass)
+	if err != nil {
+		if !errors.Is(err, nexus.ErrNotFound) {
+			_, _ = fmt.Fprintln(os.Stderr, "warn: cache: nexus:", err)
+		}
+		return nil, ""
+	}
+	rep, err = cache.ParseReport(b)
+	if err != nil || !cache.Match(rep, key) {
+		return nil, ""
+	}
+
+	bottlePath = filepath.Join(workdir, planned.BottleFile)
+	if err := up.Download(ctx, planned.NexusURLBottle, bottlePath, user, pass); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: cache: download bottle:", err)
+		return nil, ""
+	}
+	if ok, err := cache.Verify(bottlePath, rep.Sha256); err != nil || !ok {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: cache: bottle from nexus does not match sha256 in report, rebuilding")
+		_ = os.Remove(bottlePath)
+		return nil, ""
+	}
+
+	rep.CacheHit = "nexus"
+	return rep, bottlePath
+}
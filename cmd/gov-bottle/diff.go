@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/cli"
+	"gov-brew-bottle-creation/internal/config"
+	"gov-brew-bottle-creation/internal/diff"
+	"gov-brew-bottle-creation/internal/nexus"
+)
+
+// runDiff: Inhalt zweier Bottles vergleichen (lokale Pfade oder Nexus-URLs).
+func runDiff(ctx context.Context, envCfg config.Config, args []string) int {
+	cfg, err := cli.ParseDiffFlags(args)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	tmp, err := os.MkdirTemp("", "gov-bottle-diff-")
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	defer os.RemoveAll(tmp)
+
+	var sides [2]diff.Side
+	for i, src := range []string{cfg.Old, cfg.New} {
+		p := src
+		if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+			// je Seite ein Unterverzeichnis, beide URLs dürfen denselben Dateinamen haben
+			dir := filepath.Join(tmp, fmt.Sprint(i))
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+				return 1
+			}
+			p = filepath.Join(dir, path.Base(src))
+			if err := (nexus.Uploader{Client: httpClient}).Download(ctx, src, p, envCfg.NexusUser, envCfg.NexusPass); err != nil {
+				return fail("download", err)
+			}
+		}
+		if sides[i], err = diff.Read(p, src); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "error: read %s: %v\n", src, err)
+			return 1
+		}
+	}
+	res := diff.Compare(sides[0], sides[1], cfg.Threshold)
+
+	var w io.Writer = os.Stdout
+	if cfg.Out != "" {
+		f, err := os.Create(cfg.Out)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error: create output:", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if cfg.Format == "json" {
+		err = diff.WriteJSON(w, res)
+	} else {
+		err = diff.WriteText(w, res)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: write output:", err)
+		return 1
+	}
+
+	// --json: Zusammenfassung für Menschen und JSON für CI im selben Lauf
+	if cfg.JSON != "" {
+		if err := writeDiffJSON(cfg.JSON, res); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error: write json:", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+func writeDiffJSON(p string, res diff.Result) error {
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	if err := diff.WriteJSON(f, res); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"gov-brew-bottle-creation/internal/brew"
+	"gov-brew-bottle-creation/internal/nexus"
+)
+
+// Exit-Codes: 1 allgemein, 2 Aufruf/Konfiguration, ab 3 nach Fehlerart
+const (
+	exitBrew      = 3  // brew (install/bottle/info) fehlgeschlagen
+	exitNexusAuth = 4  // 401/403 von Nexus
+	exitNexus     = 5  // andere HTTP-Fehler von Nexus
+	exitRetry     = 6  // 408/429/5xx: später nochmals versuchen
+	exitIntegrity = 7  // Prüfsumme abgelehnt
+	exitTLS       = 8  // Zertifikat/TLS
+	exitInvalid   = 9  // Bottle-Validierung mit Fehlern, Upload blockiert
+	exitRepro     = 10 // --repro-check: zwei Builds unterschiedlich
+	exitCanceled  = 130
+)
+
+// exitCode: Exit-Code zur (ersten erkennbaren) Fehlerart in err
+func exitCode(err error) int {
+	var (
+		ie *nexus.IntegrityError
+		he *nexus.HTTPError
+		be *brew.ExitError
+	)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, context.Canceled):
+		return exitCanceled
+	case errors.As(err, &ie):
+		return exitIntegrity
+	case errors.As(err, &he):
+		switch {
+		case he.Status == http.StatusUnauthorized || he.Status == http.StatusForbidden:
+			return exitNexusAuth
+		case he.Retryable:
+			return exitRetry
+		}
+		return exitNexus
+	case errors.As(err, &be):
+		return exitBrew
+	case isTLSError(err):
+		return exitTLS
+	}
+	return 1
+}
+
+// hint: Hinweis zur Fehlerart, leer wenn es keinen gibt
+func hint(err error) string {
+	var (
+		ie *nexus.IntegrityError
+		he *nexus.HTTPError
+		be *brew.ExitError
+	)
+	switch {
+	case errors.As(err, &ie) && ie.Stored && !ie.Removed:
+		return fmt.Sprintf("%s changed while uploading and the stored copy could not be deleted; remove %s in Nexus before uploading again", ie.File, ie.URL)
+	case errors.As(err, &ie):
+		return fmt.Sprintf("checksum of %s was rejected or the file changed while uploading; check the file (sha256 %s) and upload again", ie.File, ie.Sums.SHA256)
+	case errors.As(err, &he):
+		switch {
+		case he.Status == http.StatusUnauthorized:
+			return "401: check NEXUS_USER and NEXUS_PASS"
+		case he.Status == http.StatusForbidden:
+			return "403: NEXUS_USER lacks permission on this repository (upload needs nx-repository-view-*-*-add/edit)"
+		case he.Status == http.StatusNotFound:
+			return "404: check NEXUS_BASE_URL / --nexus-base (repository name and path)"
+		case he.Status == http.StatusBadRequest && he.Method == http.MethodPut:
+			return "400: the repository may not allow redeploy of an existing version, or the path does not fit the repository format"
+		case he.Status == http.StatusRequestEntityTooLarge:
+			return "413: a proxy or Nexus limits the upload size"
+		case he.Retryable:
+			return fmt.Sprintf("%d is temporary: try again later", he.Status)
+		}
+	case errors.As(err, &be):
+		if be.NotFound() {
+			return "brew not found: check BREW_BIN"
+		}
+	case isTLSError(err):
+		return "TLS: set TLS_CA_BUNDLE for an internal CA (TLS_INSECURE only for testing)"
+	}
+	return ""
+}
+
+func isTLSError(err error) bool {
+	var (
+		ve *tls.CertificateVerificationError
+		ua x509.UnknownAuthorityError
+		he x509.HostnameError
+		ce x509.CertificateInvalidError
+		ae tls.AlertError
+	)
+	return errors.As(err, &ve) || errors.As(err, &ua) || errors.As(err, &he) || errors.As(err, &ce) || errors.As(err, &ae)
+}
+
+// fail: Fehler mit Hinweis ausgeben und den passenden Exit-Code liefern
+func fail(prefix string, err error) int {
+	_, _ = fmt.Fprintf(os.Stderr, "error: %s: %v\n", prefix, err)
+	if h := hint(err); h != "" {
+		_, _ = fmt.Fprintln(os.Stderr, "hint:", h)
+	}
+	return exitCode(err)
+}
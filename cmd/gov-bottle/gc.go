@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/cli"
+	"gov-brew-bottle-creation/internal/config"
+	"gov-brew-bottle-creation/internal/fsutil"
+	"gov-brew-bottle-creation/internal/gc"
+	"gov-brew-bottle-creation/internal/lock"
+)
+
+// runGC: gov-bottle gc – alte Versionen, work-* Reste und verwaiste Dateien in dist/ aufräumen.
+func runGC(ctx context.Context, envCfg config.Config, args []string) int {
+	cfg, err := cli.ParseGCFlags(args)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	workdir := firstNonEmpty(cfg.WorkDir, envCfg.DefaultWorkdir)
+
+	opts := gc.Options{
+		Workdir:      workdir,
+		KeepVersions: cfg.Keep,
+		WorkMaxAge:   cfg.WorkMaxAge,
+		Orphans:      cfg.Orphans,
+	}
+
+	// Läuft gerade ein Run auf dem Workdir, bleiben seine Formulae und work-* Dirs unangetastet
+	lockOpt, err := lockOptions("0", envCfg.LockStaleAfter)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	lk, err := lock.Acquire(ctx, lock.WorkdirPath(workdir), lockOpt)
+	var le *lock.ErrLocked
+	switch {
+	case err == nil:
+		defer releaseLock(lk)
+	case errors.As(err, &le):
+		names := holderFormulae(le.Holder.Command)
+		if len(names) == 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %s is in use by %s and the run's formulae are unknown, try again later\n", workdir, le.Holder)
+			return 1
+		}
+		fmt.Printf("note: %s is in use by %s, skipping %s\n", workdir, le.Holder, strings.Join(names, ", "))
+		opts.ProtectFormulas = map[string]bool{}
+		for _, n := range names {
+			opts.ProtectFormulas[n] = true
+		}
+		opts.ProtectSince = le.Holder.Started
+	default:
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	res, err := gc.Plan(opts)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: gc:", err)
+		return 1
+	}
+
+	var freed int64
+	var errs []error
+	if !cfg.DryRun {
+		freed, errs = gc.Apply(res)
+	}
+
+	if cfg.JSON {
+		out := struct {
+			gc.Result
+			DryRun bool     `json:"dry_run"`
+			Freed  int64    `json:"freed"`
+			Errors []string `json:"errors,omitempty"`
+		}{Result: res, DryRun: cfg.DryRun, Freed: freed}
+		for _, e := range errs {
+			out.Errors = append(out.Errors, e.Error())
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(out)
+	} else {
+		verb := "removed"
+		if cfg.DryRun {
+			verb = "would remove"
+		}
+		for _, it := range res.Items {
+			fmt.Printf("%s %s (%s, %s: %s)\n", verb, it.Path, fsutil.HumanSize(it.Size), it.Reason, it.Detail)
+		}
+		for _, e := range errs {
+			_, _ = fmt.Fprintln(os.Stderr, "error:", e)
+		}
+		if cfg.DryRun {
+			fmt.Printf("dry-run: %d entries, %s would be freed\n", len(res.Items), fsutil.HumanSize(res.Bytes))
+		} else {
+			fmt.Printf("removed %d entries, %s freed\n", len(res.Items)-len(errs), fsutil.HumanSize(freed))
+		}
+	}
+
+	if len(errs) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// holderFormulae: --ref Werte aus der Kommandozeile des Lock-Halters
+func holderFormulae(command string) []string {
+	args := strings.Fields(command)
+	var names []string
+	for i, a := range args {
+		var ref string
+		switch {
+		case (a == "--ref" || a == "-ref") && i+1 < len(args):
+			ref = args[i+1]
+		case strings.HasPrefix(a, "--ref="), strings.HasPrefix(a, "-ref="):
+			ref = a[strings.IndexByte(a, '=')+1:]
+		default:
+			continue
+		}
+		names = append(names, refFormula(ref))
+	}
+	return names
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"gov-brew-bottle-creation/internal/config"
+	"gov-brew-bottle-creation/internal/httpclient"
+)
+
+// httpClient für alle ausgehenden Requests (Nexus, OCI, Notifications); gesetzt in setupHTTP
+var httpClient = http.DefaultClient
+
+// setupHTTP baut den Client aus TLS_*/PROXY_* (CA Bundle, mTLS, min. TLS-Version, Proxy).
+func setupHTTP(envCfg config.Config) int {
+	c, err := httpclient.New(httpclient.Options{
+		CABundle:   envCfg.TLSCABundle,
+		ClientCert: envCfg.TLSClientCert,
+		ClientKey:  envCfg.TLSClientKey,
+		MinVersion: envCfg.TLSMinVersion,
+		Proxy:      envCfg.ProxyURL,
+		NoProxy:    envCfg.NoProxy,
+		Insecure:   envCfg.TLSInsecure,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: http client:", err)
+		return 2
+	}
+	if envCfg.TLSInsecure {
+		_, _ = fmt.Fprintln(os.Stderr, "********************************************************************")
+		_, _ = fmt.Fprintln(os.Stderr, "WARNING: TLS_INSECURE is set - TLS certificates are NOT verified.")
+		_, _ = fmt.Fprintln(os.Stderr, "WARNING: Nexus credentials and uploads can be intercepted.")
+		_, _ = fmt.Fprintln(os.Stderr, "WARNING: use TLS_CA_BUNDLE for internal CAs instead.")
+		_, _ = fmt.Fprintln(os.Stderr, "********************************************************************")
+	}
+	if envCfg.ProxyURL == "" && len(envCfg.NoProxy) > 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: PROXY_NO_PROXY is ignored without PROXY_URL (NO_PROXY applies to the environment proxy)")
+	}
+	httpClient = c
+	return 0
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/brew"
+	"gov-brew-bottle-creation/internal/linkage"
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// analyzeLinkage: Linkage der Binaries in den Report; Fehler und Findings nur als Warnung
+func analyzeLinkage(ctx context.Context, brewBin, prefix string, rep *report.BottleReport, bottlePath string) {
+	opts := linkage.Options{Formula: rep.Formula, Prefix: prefix}
+
+	// depends_on aus brew info; ohne brew info kein Vergleich
+	fi, err := brew.Client{BrewPath: brewBin}.FormulaInfo(ctx, rep.Ref)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: linkage: brew info:", err)
+	} else {
+		opts.Declared = append([]string{}, fi.Dependencies...)
+	}
+
+	res, err := linkage.Analyze(bottlePath, opts)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: linkage:", err)
+		return
+	}
+	rep.Linkage = res
+
+	formulae := "-"
+	if len(res.Formulae) > 0 {
+		formulae = strings.Join(res.Formulae, ", ")
+	}
+	fmt.Printf("linkage: %d binaries, links against: %s\n", len(res.Files), formulae)
+	for _, f := range res.Findings {
+		where := ""
+		if f.Path != "" {
+			where = " " + f.Path
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "warn: linkage %s%s: %s\n", f.Rule, where, f.Message)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gov-brew-bottle-creation/internal/lock"
+)
+
+// lockOptions: --lock-timeout/--lock-stale (bzw. LOCK_TIMEOUT/LOCK_STALE_AFTER) auswerten
+func lockOptions(timeout, stale string) (lock.Options, error) {
+	var opt lock.Options
+	var err error
+	if opt.Timeout, err = parseLockDuration(timeout); err != nil {
+		return opt, fmt.Errorf("lock timeout: %w", err)
+	}
+	if opt.StaleAfter, err = parseLockDuration(stale); err != nil {
+		return opt, fmt.Errorf("lock stale: %w", err)
+	}
+	if opt.StaleAfter < 0 {
+		opt.StaleAfter = 0
+	}
+	opt.OnWait = func(h lock.Holder) {
+		fmt.Println("waiting for lock held by", h)
+	}
+	opt.OnStale = func(h lock.Holder) {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: removing stale lock held by", h)
+	}
+	return opt, nil
+}
+
+func parseLockDuration(s string) (time.Duration, error) {
+	switch s {
+	case "", "0":
+		return 0, nil
+	case "-1":
+		return -1, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// acquireLock: Lock holen oder mit Hinweis auf den Halter abbrechen
+func acquireLock(ctx context.Context, path, what string, opt lock.Options) (*lock.Lock, int) {
+	lk, err := lock.Acquire(ctx, path, opt)
+	if err != nil {
+		var le *lock.ErrLocked
+		if errors.As(err, &le) {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %s is in use: %v\n", what, err)
+			_, _ = fmt.Fprintln(os.Stderr, "hint: wait with --lock-timeout, or remove the lock file if the holder is gone")
+			return nil, 1
+		}
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return nil, 1
+	}
+	return lk, 0
+}
+
+func releaseLock(lk *lock.Lock) {
+	if err := lk.Release(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn:", err)
+	}
+}
@@ -4,31 +4,68 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
 
-	"gov-brew-bottle-creation/internal/brew"
 	"gov-brew-bottle-creation/internal/cli"
 	"gov-brew-bottle-creation/internal/config"
 	"gov-brew-bottle-creation/internal/formula"
 	"gov-brew-bottle-creation/internal/fsutil"
 	"gov-brew-bottle-creation/internal/hash"
+	"gov-brew-bottle-creation/internal/junit"
+	"gov-brew-bottle-creation/internal/lock"
+	"gov-brew-bottle-creation/internal/metrics"
 	"gov-brew-bottle-creation/internal/nexus"
+	"gov-brew-bottle-creation/internal/notify"
+	"gov-brew-bottle-creation/internal/oci"
 	"gov-brew-bottle-creation/internal/plan"
 	"gov-brew-bottle-creation/internal/report"
+	"gov-brew-bottle-creation/internal/sign"
+	"gov-brew-bottle-creation/internal/state"
+	"gov-brew-bottle-creation/internal/upload"
 )
 
 func main() {
 	os.Exit(run())
 }
 
-func run() int {
+func run() (rc int) {
 	// 1) Load .env (if present)
 	config.LoadEnv()
 
 	// 2) Read env config
 	envCfg := config.FromEnv()
 
+	// TLS/Proxy für alle ausgehenden Requests, auch in den Subcommands
+	if rc := setupHTTP(envCfg); rc != 0 {
+		return rc
+	}
+
+	// Subcommands (gov-bottle <cmd> ...); ohne Subcommand läuft der normale Flow
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sign":
+			return runSign(context.Background(), envCfg, os.Args[2:])
+		case "verify-signature":
+			return runVerifySignature(envCfg, os.Args[2:])
+		case "scan":
+			return runScan(context.Background(), envCfg, os.Args[2:])
+		case "serve":
+			return runServe(envCfg, os.Args[2:])
+		case "gc":
+			return runGC(context.Background(), envCfg, os.Args[2:])
+		case "sync":
+			return runSync(envCfg, os.Args[2:])
+		case "diff":
+			return runDiff(context.Background(), envCfg, os.Args[2:])
+		}
+	}
+
 	// 3) Parse CLI flags
 	cliCfg, err := cli.ParseFlags(os.Args[1:])
 	if err != nil {
@@ -36,11 +73,48 @@ func run() int {
 		return 2
 	}
 
+	// Metriken als node_exporter textfile (zuerst registriert -> läuft als letztes defer)
+	if mf := firstNonEmpty(cliCfg.MetricsFile, envCfg.MetricsFile); mf != "" {
+		defer writeMetrics(mf)
+	}
+
+	// Chat/Webhook-Benachrichtigung nach dem Run, auch bei frühen Fehlern (Fehler ändern den Exit-Code nicht)
+	var notifiers []notify.Notifier
+	var events []notify.Event // ein Event pro Ref bzw. hochgeladenem Bottle
+	if nc := firstNonEmpty(cliCfg.NotifyConfig, envCfg.NotifyConfig); nc != "" && !cliCfg.DryRun {
+		if notifiers, err = notify.Load(nc); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+			return 2
+		}
+	}
+	if len(notifiers) > 0 {
+		defer func() {
+			if len(events) == 0 {
+				// abgebrochen bevor ein Ref lief (Lock, Flags, keine Bottles, ...)
+				r := report.BottleReport{Tag: firstNonEmpty(cliCfg.Tag, envCfg.DefaultTag)}
+				if len(cliCfg.Refs) > 0 {
+					r.Ref, r.Formula = cliCfg.Refs[0], refFormula(cliCfg.Refs[0])
+				}
+				events = append(events, notify.NewEvent(r, rc))
+			}
+			// eigener Timeout: nach Ctrl-C ist ctx schon abgebrochen, die Meldung soll trotzdem raus
+			nctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			for _, ev := range events {
+				if err := (notify.Sender{Client: httpClient}).Send(nctx, notifiers, ev); err != nil {
+					_, _ = fmt.Fprintln(os.Stderr, "warn:", err)
+				}
+			}
+		}()
+	}
+
 	// 4) Merge (flags override env)
 	finalTag := firstNonEmpty(cliCfg.Tag, envCfg.DefaultTag)
 	finalWorkdir := firstNonEmpty(cliCfg.WorkDir, envCfg.DefaultWorkdir)
 	finalNexusBase := firstNonEmpty(cliCfg.NexusBase, envCfg.NexusBaseURL)
 	finalTapWorkdir := firstNonEmpty(cliCfg.TapWorkdir, envCfg.TapWorkdir)
+	finalOCIRegistry := firstNonEmpty(cliCfg.OCIRegistry, envCfg.OCIRegistry)
+	finalOCINamespace := firstNonEmpty(cliCfg.OCINamespace, envCfg.OCINamespace)
 
 	// 5) Validate
 	if finalTag == "" {
@@ -56,13 +130,46 @@ func run() int {
 		return 2
 	}
 
+	if cliCfg.OCIPush && finalOCIRegistry == "" {
+		_, _ = fmt.Fprintln(os.Stderr, "error: missing oci registry. Set --oci-registry or OCI_REGISTRY in .env")
+		return 2
+	}
+
 	// Workdir sicherstellen (immer)
 	if err := os.MkdirAll(finalWorkdir, 0o755); err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, "error: failed to create workdir:", err)
 		return 1
 	}
 
-	ctx := context.Background()
+	// Ctrl-C/SIGTERM: laufende Uploads sauber abbrechen, Locks freigeben
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Advisory-Lock auf dem Workdir: parallele Runs auf demselben dist/ überschreiben sich sonst
+	lockOpt, err := lockOptions(firstNonEmpty(cliCfg.LockTimeout, envCfg.LockTimeout), firstNonEmpty(cliCfg.LockStale, envCfg.LockStaleAfter))
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	workdirLock, lrc := acquireLock(ctx, lock.WorkdirPath(finalWorkdir), "workdir "+finalWorkdir, lockOpt)
+	if lrc != 0 {
+		return lrc
+	}
+	defer releaseLock(workdirLock)
+
+	// JUnit: eine Testcase-Gruppe (classname) pro Ref bzw. Bottle, ein Testcase pro Schritt;
+	// ein Fehler ausserhalb der Gruppen (Lock, Upload-Setup, ...) landet als Testcase "run"
+	jr := junit.New("gov-bottle", "")
+	if cliCfg.JUnitFile != "" {
+		defer func() {
+			if !jr.Failed() {
+				jr.Close(rc, "")
+			}
+			if err := jr.WriteFile(cliCfg.JUnitFile); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "warn: junit:", err)
+			}
+		}()
+	}
 
 	// ------------------------------------------------------------
 	// Upload-only: --nexus-upload (kein ref nötig, kein plan, kein build)
@@ -73,44 +180,40 @@ func run() int {
 			return 2
 		}
 
-		bottlePath, err := fsutil.FindBottleTarGz(finalWorkdir)
+		// Auswahl über Dateiname + Report statt "neuestes Bottle gewinnt"
+		filter := fsutil.Filter{Version: cliCfg.FormulaVersion, Tag: finalTag}
+		for _, r := range cliCfg.Refs {
+			filter.Formulae = append(filter.Formulae, refFormula(r))
+		}
+		bottles, err := fsutil.FindBottles(finalWorkdir, filter)
 		if err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: failed to find bottle in workdir:", err)
+			_, _ = fmt.Fprintln(os.Stderr, "error:", err)
 			return 1
 		}
-		bottleFile := filepath.Base(bottlePath)
-
-		const suffix = ".bottle.tar.gz"
-		if len(bottleFile) <= len(suffix) || bottleFile[len(bottleFile)-len(suffix):] != suffix {
-			_, _ = fmt.Fprintln(os.Stderr, "error: bottle filename does not end with .bottle.tar.gz:", bottleFile)
+		if len(bottles) == 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "error: no bottle in %s matches %s\n", finalWorkdir, filter)
 			return 1
 		}
-
-		jsonFile := bottleFile[:len(bottleFile)-len(suffix)] + ".bottle.json"
-		jsonPath := filepath.Join(finalWorkdir, jsonFile)
-
-		if _, err := os.Stat(jsonPath); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: bottle json file not found:", jsonPath)
+		if len(bottles) > 1 && !cliCfg.UploadAll {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %d bottles in %s match %s:\n", len(bottles), finalWorkdir, filter)
+			for _, b := range bottles {
+				_, _ = fmt.Fprintln(os.Stderr, "  "+filepath.Base(b.Path))
+			}
+			_, _ = fmt.Fprintln(os.Stderr, "hint: narrow down with --ref/--formula-version/--tag or upload all with --all")
 			return 1
 		}
 
-		bottleURL := joinURL(finalNexusBase, bottleFile)
-		jsonURL := joinURL(finalNexusBase, jsonFile)
-
-		up := nexus.Uploader{}
-
-		if err := up.PutFile(ctx, bottleURL, bottlePath, envCfg.NexusUser, envCfg.NexusPass); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: upload bottle:", err)
-			return 1
+		u, err := newUploader(envCfg.NexusUser, envCfg.NexusPass, cliCfg.UploadJobs, envCfg.UploadJobs, firstNonEmpty(cliCfg.BandwidthLimit, envCfg.UploadLimit))
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+			return 2
 		}
-		if err := up.PutFile(ctx, jsonURL, jsonPath, envCfg.NexusUser, envCfg.NexusPass); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: upload json:", err)
-			return 1
+		u.sha256File = cliCfg.SHA256File || envCfg.SHA256File
+		rc = uploadExisting(ctx, envCfg, u, bottles, finalNexusBase, jr)
+		for _, b := range bottles {
+			events = append(events, notify.NewEvent(b.Report, rc))
 		}
-
-		fmt.Println("upload bottle to:", bottleURL)
-		fmt.Println("upload bottle json to:", jsonURL)
-		return 0
+		return rc
 	}
 
 	// ------------------------------------------------------------
@@ -120,14 +223,140 @@ func run() int {
 		_, _ = fmt.Fprintln(os.Stderr, "error: missing --ref")
 		return 2
 	}
-	ref := cliCfg.Refs[0]
+
+	// Upload-Einstellungen früh prüfen, nicht erst nach dem Build
+	var up uploader
+	if cliCfg.Upload && !cliCfg.DryRun {
+		if up, err = newUploader(envCfg.NexusUser, envCfg.NexusPass, cliCfg.UploadJobs, envCfg.UploadJobs, firstNonEmpty(cliCfg.BandwidthLimit, envCfg.UploadLimit)); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+			return 2
+		}
+		up.sha256File = cliCfg.SHA256File || envCfg.SHA256File
+	}
+
+	var signer sign.Signer
+	if cliCfg.Sign && !cliCfg.DryRun {
+		var rc int
+		if signer, rc = loadSigner(ctx, firstNonEmpty(cliCfg.SignKey, envCfg.SignKey)); rc != 0 {
+			return rc
+		}
+	}
+
+	s := refSettings{
+		envCfg: envCfg, cliCfg: cliCfg, jr: jr, lockOpt: lockOpt,
+		tag: finalTag, workdir: finalWorkdir, nexusBase: finalNexusBase, tapWorkdir: finalTapWorkdir,
+		ociRegistry: finalOCIRegistry, ociNamespace: finalOCINamespace,
+		up: up, signer: signer, events: &events,
+	}
+
+	// mehrere --ref: nacheinander im selben Workdir; ein Fehler bricht die übrigen nicht ab,
+	// Exit-Code ist der des ersten fehlgeschlagenen Refs
+	for _, ref := range cliCfg.Refs {
+		if ctx.Err() != nil {
+			break
+		}
+		jr.Group(ref)
+		refRC := runRef(ctx, s, ref)
+		jr.Close(refRC, "")
+		if refRC != 0 && rc == 0 {
+			rc = refRC
+		}
+	}
+	if ctx.Err() != nil && rc == 0 {
+		return exitCanceled
+	}
+	return rc
+}
+
+// refSettings: Einstellungen eines Runs, gleich für alle --ref
+type refSettings struct {
+	envCfg  config.Config
+	cliCfg  cli.Config
+	jr      *junit.Recorder
+	lockOpt lock.Options
+
+	tag, workdir, nexusBase, tapWorkdir string
+	ociRegistry, ociNamespace           string
+
+	up     uploader
+	signer sign.Signer
+	events *[]notify.Event // Ergebnis pro Ref für die Benachrichtigung am Ende
+}
+
+// runRef: Plan, Build, Push, Formula-Update und Upload für einen Ref
+func runRef(ctx context.Context, s refSettings, ref string) (rc int) {
+	envCfg, cliCfg, jr, lockOpt := s.envCfg, s.cliCfg, s.jr, s.lockOpt
+	finalTag, finalWorkdir, finalNexusBase, finalTapWorkdir := s.tag, s.workdir, s.nexusBase, s.tapWorkdir
+	finalOCIRegistry, finalOCINamespace := s.ociRegistry, s.ociNamespace
+	up, signer := s.up, s.signer
+	var err error
+
+	var rep report.BottleReport
+	defer func() {
+		if rep.Formula == "" {
+			rep.Ref, rep.Formula, rep.Tag = ref, refFormula(ref), finalTag
+		}
+		*s.events = append(*s.events, notify.NewEvent(rep, rc))
+	}()
+
+	// root_url im bottle-Block: Nexus (raw) oder OCI Registry
+	rootURL := finalNexusBase
+	if cliCfg.OCIPush {
+		if finalOCINamespace == "" {
+			tap, _, err := formula.ParseRef(ref)
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error: parse ref:", err)
+				return 2
+			}
+			finalOCINamespace = tap
+		}
+		if err := oci.CheckNamespace(finalOCINamespace); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+			return 2
+		}
+		// ghcr.io Form; brew install braucht HOMEBREW_ARTIFACT_DOMAIN=<registry> (siehe oci.RootURL)
+		rootURL = oci.RootURL(finalOCINamespace)
+	}
 
 	// Plan erstellen
+	jr.Begin("plan")
 	pl := plan.Plan(ctx, envCfg.BrewBin, ref, finalTag, finalNexusBase, joinURL)
-	rep := pl.Report
+	rep = pl.Report
 	bottleName := pl.BottleName
 	jsonName := pl.JSONName
 
+	// Metriken pro Run; leeres Resultat = Schritt nicht angefordert
+	var buildResult, uploadResult string
+	defer func() {
+		if rep.Formula == "" {
+			return
+		}
+		if buildResult != "" {
+			metrics.BuildsTotal.Inc(rep.Formula, finalTag, buildResult)
+		}
+		if uploadResult != "" {
+			metrics.UploadsTotal.Inc(rep.Formula, finalTag, uploadResult)
+		}
+		metrics.LastRun.Set(float64(time.Now().Unix()), rep.Formula, finalTag)
+	}()
+
+	// Checkpoint pro Ref im Workdir (--resume überspringt fertige Schritte)
+	var cp *checkpoint
+	var resumedBuild *state.Step
+	if !cliCfg.DryRun && rep.Status != report.StatusFailed && (cliCfg.BuildBottle || cliCfg.Upload || cliCfg.OCIPush) {
+		if cp, err = openCheckpoint(finalWorkdir, rep, cliCfg.Resume); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		if cliCfg.BuildBottle {
+			resumedBuild = cp.done(state.StepBuild)
+		}
+		if prev := cp.report(); prev != nil && resumedBuild != nil {
+			prev.NexusURLBottle, prev.NexusURLJSON = rep.NexusURLBottle, rep.NexusURLJSON
+			rep = *prev
+		}
+	}
+
 	// Report schreiben helper
 	outPath := filepath.Join(finalWorkdir, jsonName)
 	writeReport := func() int {
@@ -136,17 +365,57 @@ func run() int {
 			_, _ = fmt.Fprintln(os.Stderr, "error: create json:", err)
 			return 1
 		}
-		defer f.Close()
 
 		enc := json.NewEncoder(f)
 		enc.SetIndent("", "  ")
-		if err := enc.Encode(rep); err != nil {
+		err = enc.Encode(rep)
+		_ = f.Close()
+		if err != nil {
 			_, _ = fmt.Fprintln(os.Stderr, "error: write json:", err)
 			return 1
 		}
+
+		// Kopie z.B. für den serve-Modus
+		if cliCfg.ReportFile != "" {
+			if err := copyFile(outPath, cliCfg.ReportFile); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error: write report file:", err)
+				return 1
+			}
+		}
+
+		cp.saveReport(rep)
+
+		// json.sig muss immer zum aktuellen Report passen
+		if signer != nil && rep.SignatureKeyID != "" {
+			if _, err := sign.SignFile(ctx, signer, outPath); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error: sign json:", err)
+				return 1
+			}
+		}
 		return 0
 	}
 
+	// Build-Cache: verifiziertes Bottle mit gleichem Key in dist/ oder Nexus -> kein install/bottle
+	// (muss vor dem initialen Report laufen, sonst wäre der alte Report in dist/ überschrieben)
+	var cachedBottle string
+	if cliCfg.BuildBottle && !cliCfg.DryRun && rep.Status != report.StatusFailed && resumedBuild == nil {
+		key, err := cacheKey(ctx, envCfg.BrewBin, ref, finalTag)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "warn: cache key:", err)
+		} else {
+			rep.CacheKey = key
+			if !cliCfg.ForceBuild {
+				hit, p := lookupCache(ctx, nexus.Uploader{Client: httpClient}, envCfg.NexusUser, envCfg.NexusPass, finalWorkdir, rep, key)
+				if hit != nil {
+					// Upload-Ziele aus dem aktuellen Plan (nexus-base kann sich geändert haben)
+					hit.NexusURLBottle, hit.NexusURLJSON = rep.NexusURLBottle, rep.NexusURLJSON
+					rep = *hit
+					cachedBottle = p
+				}
+			}
+		}
+	}
+
 	// initial report
 	if rc := writeReport(); rc != 0 {
 		return rc
@@ -154,15 +423,41 @@ func run() int {
 
 	// Plan failed?
 	if rep.Status == report.StatusFailed {
+		if cliCfg.BuildBottle && !cliCfg.DryRun {
+			buildResult = "failure"
+		}
+		jr.Fail("plan failed: "+rep.Error, rep.Error)
 		_, _ = fmt.Fprintln(os.Stderr, "error: plan failed:", rep.Error)
 		fmt.Println("wrote:", outPath)
+		if h := hint(pl.Err); h != "" {
+			_, _ = fmt.Fprintln(os.Stderr, "hint:", h)
+		}
+		if rc := exitCode(pl.Err); rc != 0 {
+			return rc
+		}
 		return 1
 	}
 
+	jr.Pass()
+
+	// Formula-Update als eigener JUnit-Schritt
+	updateFormula := func() int {
+		if !cliCfg.UpdateFormula {
+			return 0
+		}
+		jr.Begin("formula update")
+		if rc := maybeUpdateFormula(ref, finalWorkdir, finalTapWorkdir, rootURL, cliCfg.UpdateFormula); rc != 0 {
+			return rc
+		}
+		jr.Pass()
+		return 0
+	}
+
 	// dry-run: keine side effects
 	if cliCfg.DryRun {
-		if cliCfg.BuildBottle || cliCfg.Upload {
-			_, _ = fmt.Fprintln(os.Stderr, "note: --dry-run set, ignoring --build-bottle/--upload")
+		skipSteps(jr, cliCfg, "dry-run")
+		if cliCfg.BuildBottle || cliCfg.Upload || cliCfg.OCIPush {
+			_, _ = fmt.Fprintln(os.Stderr, "note: --dry-run set, ignoring --build-bottle/--upload/--oci-push")
 		}
 		fmt.Println("wrote:", outPath)
 		return 0
@@ -170,88 +465,240 @@ func run() int {
 
 	// Optional: build bottle
 	var bottleOutPath string
-	if cliCfg.BuildBottle {
-		workDir, err := os.MkdirTemp(finalWorkdir, "work-")
+
+	// Bottle-Struktur prüfen (gebaut oder aus dem Cache); Fehler blockieren Upload/Push und das
+	// Formula-Update (sha im bottle do Block), reiner Build meldet nur
+	validateStep := func() int {
+		jr.Begin("validate")
+		blocking := cliCfg.Upload || cliCfg.OCIPush || cliCfg.UpdateFormula
+		findings, err := validateBottle(envCfg, bottleOutPath, rep.Formula, rep.Version, blocking)
 		if err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: failed to create temp workdir:", err)
+			_, _ = fmt.Fprintln(os.Stderr, "error: validate:", err)
 			return 1
 		}
-		if !cliCfg.KeepWork {
-			defer os.RemoveAll(workDir)
-		} else {
-			fmt.Println("keeping workdir:", workDir)
+		rep.Validation = findings
+		if n := report.Errors(findings); n > 0 && blocking {
+			jr.Fail(fmt.Sprintf("%d validation error(s)", n), "")
+			if rc := writeReport(); rc != 0 {
+				return rc
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "error: bottle failed validation with %d error(s), not publishing or updating the formula\n", n)
+			_, _ = fmt.Fprintln(os.Stderr, "hint: findings are in the report (validation); size limits via BOTTLE_MIN_SIZE/BOTTLE_MAX_SIZE/BOTTLE_MAX_UNPACKED")
+			return exitInvalid
 		}
+		jr.Pass()
+		return 0
+	}
 
-		brewBin := envCfg.BrewBin
-
-		// uninstall (Fehler nur loggen)
-		_, stderr, code, err := brew.Run(ctx, brewBin,
-			[]string{"uninstall", "--ignore-dependencies", ref},
-			"", nil,
-		)
-		if err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "warn: uninstall failed:", err)
-			_, _ = fmt.Fprintln(os.Stderr, "stderr:", stderr)
-			_, _ = fmt.Fprintln(os.Stderr, "exit:", code)
-		}
+	if cliCfg.BuildBottle && cachedBottle != "" {
+		bottleOutPath = cachedBottle
+		buildResult = "cached"
+		fmt.Printf("cache hit (%s): reusing %s, skipping install/bottle (use --force-build to rebuild)\n", rep.CacheHit, bottleOutPath)
+		jr.Skip("build", "cache hit ("+rep.CacheHit+")")
 
-		// install --build-bottle
-		_, stderr, code, err = brew.Run(ctx, brewBin,
-			[]string{"install", "--build-bottle", ref},
-			workDir, nil,
-		)
+		// nur der Build entfällt: Hash und Validierung auch für Bottles aus dem Cache
+		jr.Begin("hash")
+		sum, err := hash.FileSHA256(bottleOutPath)
 		if err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: build-bottle failed:", err)
-			_, _ = fmt.Fprintln(os.Stderr, "stderr:", stderr)
-			_, _ = fmt.Fprintln(os.Stderr, "exit:", code)
+			_, _ = fmt.Fprintln(os.Stderr, "error: sha256:", err)
 			return 1
 		}
-
-		// brew bottle
-		_, stderr, code, err = brew.Run(ctx, brewBin,
-			[]string{"bottle", "--no-rebuild", ref},
-			workDir, nil,
-		)
-		if err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: brew bottle failed:", err)
-			_, _ = fmt.Fprintln(os.Stderr, "stderr:", stderr)
-			_, _ = fmt.Fprintln(os.Stderr, "exit:", code)
+		if sum != rep.Sha256 {
+			jr.Fail("cached bottle does not match the sha256 in its report", "")
+			_, _ = fmt.Fprintf(os.Stderr, "error: cached bottle %s has sha256 %s, report says %s\n", bottleOutPath, sum, rep.Sha256)
+			_, _ = fmt.Fprintln(os.Stderr, "hint: rebuild with --force-build")
 			return 1
 		}
+		jr.Pass()
+		if rc := validateStep(); rc != 0 {
+			return rc
+		}
+		// wie nach dem Build: Bottle.sig neu mit dem aktuellen Key (Upload verlangt sie mit Signer)
+		if signer != nil {
+			sigPath, err := sign.SignFile(ctx, signer, bottleOutPath)
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+				return 1
+			}
+			rep.SignatureKeyID = signer.KeyID()
+			fmt.Println("wrote:", sigPath)
+		}
+		if rc := writeReport(); rc != 0 {
+			return rc
+		}
+	} else if cliCfg.BuildBottle {
+		var buildStarted, buildFinished time.Time
 
-		produced, err := fsutil.FindBottleTarGz(workDir)
+		// --reproducible: SOURCE_DATE_EPOCH auch an brew, Bottle danach normalisieren
+		reproducible := cliCfg.Reproducible || envCfg.Reproducible
+		epoch, err := sourceDateEpoch(envCfg.SourceEpoch)
 		if err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: failed to find bottle:", err)
-			return 1
+			_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+			return 2
 		}
+		var brewEnv map[string]string
+		if reproducible {
+			brewEnv = map[string]string{"SOURCE_DATE_EPOCH": strconv.FormatInt(epoch.Unix(), 10)}
+		}
+		if resumedBuild != nil {
+			// --resume: Bottle aus dem Checkpoint ist noch da und unverändert
+			bottleOutPath = resumedBuild.Artifacts[0].Path
+			buildStarted, buildFinished = resumedBuild.Started, resumedBuild.Finished
+			buildResult = "resumed"
+			fmt.Println("resume: build finished", buildFinished.Format(time.RFC3339)+", reusing", bottleOutPath)
+			jr.Skip("build", "resumed from checkpoint")
+		} else {
+			workDir, err := os.MkdirTemp(finalWorkdir, "work-")
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error: failed to create temp workdir:", err)
+				return 1
+			}
+			if !cliCfg.KeepWork {
+				defer os.RemoveAll(workDir)
+			} else {
+				fmt.Println("keeping workdir:", workDir)
+			}
+
+			buildStarted = time.Now()
+			buildResult = "failure"
+			jr.Begin("build")
+
+			// uninstall/install/bottle verändern den Prefix -> ein Build pro Prefix
+			prefixLock, lrc := acquireLock(ctx, lock.PrefixPath(envCfg.HomebrewPrefix), "brew prefix "+envCfg.HomebrewPrefix, lockOpt)
+			if lrc != 0 {
+				return lrc
+			}
+			defer releaseLock(prefixLock)
+
+			produced, rc := brewBuild(ctx, jr, envCfg.BrewBin, ref, workDir, brewEnv)
+			if rc != 0 {
+				return rc
+			}
+			buildFinished = time.Now()
 
-		bottleOutPath = filepath.Join(finalWorkdir, bottleName)
-		if err := os.Rename(produced, bottleOutPath); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: move bottle:", err)
-			return 1
+			bottleOutPath = filepath.Join(finalWorkdir, bottleName)
+			if err := os.Rename(produced, bottleOutPath); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error: move bottle:", err)
+				return 1
+			}
+			fmt.Println("wrote:", bottleOutPath)
+
+			if reproducible {
+				if rc := normalizeBottle(bottleOutPath, epoch); rc != 0 {
+					return rc
+				}
+				rep.Reproducible = true
+			}
+
+			// --repro-check: gleich nochmals bauen (Prefix-Lock noch gehalten)
+			if cliCfg.ReproCheck {
+				ok, rc := reproCheck(ctx, jr, envCfg.BrewBin, ref, finalWorkdir, bottleOutPath, epoch, brewEnv)
+				if rc != 0 {
+					return rc
+				}
+				rep.ReproCheck = "identical"
+				if !ok {
+					rep.ReproCheck = "different"
+					if rc := writeReport(); rc != 0 {
+						return rc
+					}
+					_, _ = fmt.Fprintln(os.Stderr, "error: bottle is not reproducible")
+					_, _ = fmt.Fprintln(os.Stderr, "hint: compare the listed files; mtimes and ordering are already normalized, so the contents differ")
+					return exitRepro
+				}
+			}
+			releaseLock(prefixLock)
 		}
-		fmt.Println("wrote:", bottleOutPath)
 
+		jr.Begin("hash")
 		sum, err := hash.FileSHA256(bottleOutPath)
 		if err != nil {
 			_, _ = fmt.Fprintln(os.Stderr, "error: sha256:", err)
 			return 1
 		}
 		rep.Sha256 = sum
+		if resumedBuild == nil {
+			buildResult = "success"
+			cp.complete(state.StepBuild, buildStarted, buildFinished, state.Artifact{Path: bottleOutPath, Sha256: sum})
+		}
+		jr.Pass()
+		if fi, err := os.Stat(bottleOutPath); err == nil {
+			metrics.BottleSize.Set(float64(fi.Size()), rep.Formula, finalTag)
+		}
+
+		if rc := validateStep(); rc != 0 {
+			return rc
+		}
+
+		analyzeLinkage(ctx, envCfg.BrewBin, envCfg.HomebrewPrefix, &rep, bottleOutPath)
+
+		// Optional: detached signature fürs Bottle (json wird in writeReport signiert)
+		if signer != nil {
+			sigPath, err := sign.SignFile(ctx, signer, bottleOutPath)
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+				return 1
+			}
+			rep.SignatureKeyID = signer.KeyID()
+			fmt.Println("wrote:", sigPath)
+		}
+
+		// Optional: SBOM (CycloneDX + SPDX) neben dem Report
+		if cliCfg.SBOM {
+			if rc := writeSBOM(ctx, envCfg.BrewBin, &rep, bottleOutPath, finalWorkdir, finalNexusBase); rc != 0 {
+				return rc
+			}
+		}
+
+		// Optional: in-toto/SLSA Provenance
+		if cliCfg.Provenance {
+			if rc := writeProvenance(ctx, envCfg.BrewBin, &rep, bottleOutPath, finalWorkdir, finalNexusBase,
+				buildStarted, buildFinished, signer); rc != 0 {
+				return rc
+			}
+		}
 
 		// Report nach Build überschreiben
 		if rc := writeReport(); rc != 0 {
 			return rc
 		}
+	}
+
+	// Optional: OCI push (Layout wie ghcr.io)
+	var pushed *state.Step
+	if cliCfg.OCIPush {
+		pushed = cp.done(state.StepOCIPush)
+	}
+	if pushed != nil && pushed.Artifacts[0].Sha256 == rep.Sha256 {
+		fmt.Println("resume: bottle already pushed:", rep.OCIRepository+"@"+rep.OCIManifestDigest)
+		jr.Skip("oci push", "resumed from checkpoint")
+	} else if cliCfg.OCIPush {
+		jr.Begin("oci push")
+		pushStarted := time.Now()
+		if rc := pushOCI(ctx, &rep, bottleOutPath, finalOCIRegistry, finalOCINamespace, envCfg.OCIUser, envCfg.OCIPass); rc != 0 {
+			return rc
+		}
+		cp.complete(state.StepOCIPush, pushStarted, time.Now(), state.Artifact{Path: bottleOutPath, Sha256: rep.Sha256})
+		if rc := writeReport(); rc != 0 {
+			return rc
+		}
+		jr.Pass()
+	}
 
-		// OPTIONAL: Formula updaten (NACH build + sha)
-		if rc := maybeUpdateFormula(ref, finalWorkdir, finalTapWorkdir, finalNexusBase, cliCfg.UpdateFormula); rc != 0 {
+	// OPTIONAL: Formula updaten (NACH build + sha und erst wenn der OCI Push durch ist,
+	// sonst zeigt die Formula auf ein Bottle, das es in der Registry nicht gibt)
+	if cliCfg.BuildBottle {
+		if rc := updateFormula(); rc != 0 {
 			return rc
 		}
 	}
 
-	// Optional: upload
-	if cliCfg.Upload {
+	// Optional: upload (Cache-Treffer aus Nexus liegt schon dort)
+	if cliCfg.Upload && rep.CacheHit == "nexus" {
+		fmt.Println("skip upload: bottle already in nexus:", rep.NexusURLBottle)
+		uploadResult = "skipped"
+		jr.Skip("upload", "cache hit (nexus)")
+	} else if cliCfg.Upload {
 		if envCfg.NexusUser == "" || envCfg.NexusPass == "" {
 			_, _ = fmt.Fprintln(os.Stderr, "error: Nexus user or Nexus pass is empty")
 			return 2
@@ -262,19 +709,58 @@ func run() int {
 			bottleOutPath = filepath.Join(finalWorkdir, bottleName)
 		}
 
-		up := nexus.Uploader{}
-
-		if err := up.PutFile(ctx, rep.NexusURLBottle, bottleOutPath, envCfg.NexusUser, envCfg.NexusPass); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: upload bottle:", err)
-			return 1
+		uploadResult = "failure"
+		jr.Begin("upload")
+
+		// Bottle zuerst: der Durchsatz landet im Report, bevor dieser hochgeladen wird
+		bottleBatch := upload.Batch{Name: bottleName, Files: []upload.File{
+			{Path: bottleOutPath, URL: rep.NexusURLBottle, Required: true, Kind: "bottle"},
+		}}
+		err := up.run(ctx, []upload.Batch{bottleBatch}, cp, func(_ upload.File, p nexus.Progress) {
+			rep.UploadBytes = p.Sent
+			rep.UploadSeconds = math.Round(p.Elapsed.Seconds()*1000) / 1000
+			rep.UploadBytesPerSec = math.Round(p.Rate())
+		})
+		if err != nil {
+			return exitCode(err)
 		}
-		if err := up.PutFile(ctx, rep.NexusURLJSON, outPath, envCfg.NexusUser, envCfg.NexusPass); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "error: upload json:", err)
-			return 1
+		if rc := writeReport(); rc != 0 {
+			return rc
 		}
 
-		fmt.Println("upload bottle to:", rep.NexusURLBottle)
-		fmt.Println("upload json to:", rep.NexusURLJSON)
+		side := []upload.File{
+			{Path: outPath, URL: rep.NexusURLJSON, Required: true, Kind: "json"},
+			{Path: bottleOutPath + sign.Suffix, URL: rep.NexusURLBottle + sign.Suffix, Required: signer != nil},
+			{Path: outPath + sign.Suffix, URL: rep.NexusURLJSON + sign.Suffix, Required: signer != nil},
+		}
+		if rep.Provenance != "" {
+			provPath := filepath.Join(finalWorkdir, rep.Provenance)
+			side = append(side,
+				upload.File{Path: provPath, URL: rep.NexusURLProvenance, Required: true},
+				upload.File{Path: provPath + sign.Suffix, URL: rep.NexusURLProvenance + sign.Suffix, Required: signer != nil},
+			)
+		}
+		if rep.SBOMCycloneDX != "" {
+			side = append(side,
+				upload.File{Path: filepath.Join(finalWorkdir, rep.SBOMCycloneDX), URL: rep.NexusURLSBOMCycloneDX, Required: true},
+				upload.File{Path: filepath.Join(finalWorkdir, rep.SBOMSPDX), URL: rep.NexusURLSBOMSPDX, Required: true},
+			)
+		}
+		if up.sha256File {
+			sumPath, err := hash.WriteSidecar(bottleOutPath, rep.Sha256)
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+				return 1
+			}
+			side = append(side, upload.File{Path: sumPath, URL: rep.NexusURLBottle + hash.Suffix, Required: true})
+		}
+		// json, Signaturen, SBOMs, Provenance und .sha256 parallel
+		if err := up.run(ctx, []upload.Batch{{Name: bottleName, Files: side}}, cp, nil); err != nil {
+			return exitCode(err)
+		}
+		cp.finish(state.StepUpload)
+		uploadResult = "success"
+		jr.Pass()
 
 		// optional: report nochmals überschreiben
 		if rc := writeReport(); rc != 0 {
@@ -286,6 +772,27 @@ func run() int {
 	return 0
 }
 
+// skipSteps: angeforderte, aber nicht ausgeführte Schritte als skipped melden
+func skipSteps(jr *junit.Recorder, cfg cli.Config, reason string) {
+	if cfg.BuildBottle {
+		jr.Skip("build", reason)
+		jr.Skip("hash", reason)
+		jr.Skip("validate", reason)
+	}
+	if cfg.ReproCheck {
+		jr.Skip("repro check", reason)
+	}
+	if cfg.UpdateFormula {
+		jr.Skip("formula update", reason)
+	}
+	if cfg.OCIPush {
+		jr.Skip("oci push", reason)
+	}
+	if cfg.Upload {
+		jr.Skip("upload", reason)
+	}
+}
+
 func maybeUpdateFormula(ref, workdir, tapWorkdir, rootURL string, update bool) int {
 	if !update {
 		return 0
@@ -322,6 +829,14 @@ func maybeUpdateFormula(ref, workdir, tapWorkdir, rootURL string, update bool) i
 	return 0
 }
 
+func copyFile(src, dst string) error {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, b, 0o644)
+}
+
 func firstNonEmpty(a, b string) string {
 	if a != "" {
 		return a
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"gov-brew-bottle-creation/internal/lock"
+	"gov-brew-bottle-creation/internal/metrics"
+	"gov-brew-bottle-creation/internal/nexus"
+)
+
+// writeMetrics: bestehende .prom-Datei einlesen (Counter laufen über Runs weiter) und neu schreiben.
+// Mehrere Runs können sich METRICS_FILE teilen: lesen, addieren und schreiben (tmp + rename)
+// unter einem eigenen Lock, sonst gehen Increments des anderen Runs verloren.
+func writeMetrics(path string) {
+	lk, err := lock.Acquire(context.Background(), path+".lock", lock.Options{
+		Timeout:    30 * time.Second,
+		StaleAfter: 10 * time.Minute,
+		Poll:       100 * time.Millisecond,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: metrics:", err)
+		return
+	}
+	defer releaseLock(lk)
+
+	// in diesem Run gesetzte Gauges (last run, bottle size) sind neuer als die Datei
+	if err := metrics.Default.MergeBaseFile(path); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: metrics:", err)
+	}
+	if err := metrics.Default.WriteFile(path); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: metrics:", err)
+	}
+}
+
+// putFile: PutFile mit Fortschrittsanzeige und Durchsatz-Messung (kind: bottle, json, sidecar).
+// tty=false erzwingt Logzeilen statt einer überschriebenen Zeile (z.B. bei parallelen Uploads).
+func putFile(ctx context.Context, up nexus.Uploader, url, path, user, pass, kind string, tty bool) (nexus.Progress, error) {
+	var last nexus.Progress
+	show := uploadProgress(os.Stderr, tty)
+	up.Progress = func(p nexus.Progress) {
+		last = p
+		show(p)
+	}
+	if err := up.PutFile(ctx, url, path, user, pass); err != nil {
+		if tty && last.Elapsed >= progressTTYDelay {
+			_, _ = fmt.Fprintln(os.Stderr) // angefangene Fortschrittszeile abschließen
+		}
+		return last, err
+	}
+	if rate := last.Rate(); rate > 0 {
+		metrics.UploadThroughput.Observe(rate, kind)
+	}
+	return last, nil
+}
+
+// brewStep: Dauer eines brew-Schritts erfassen
+func brewStep(step string, started time.Time) {
+	metrics.BrewStepDuration.Observe(time.Since(started).Seconds(), step)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/bottle"
+	"gov-brew-bottle-creation/internal/oci"
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// pushOCI pusht das gebaute Bottle als OCI Artefakt und trägt Repository/Digest in den Report ein.
+func pushOCI(ctx context.Context, rep *report.BottleReport, bottlePath, registry, namespace, user, pass string) int {
+	tab, err := bottle.ReadReceipt(bottlePath)
+	if err != nil {
+		// ohne tab funktioniert brew install trotzdem
+		_, _ = fmt.Fprintln(os.Stderr, "warn: read INSTALL_RECEIPT.json:", err)
+	}
+
+	p := &oci.Pusher{Registry: registry, User: user, Pass: pass, Client: httpClient}
+	res, err := p.PushBottle(ctx, oci.Bottle{
+		Namespace: namespace,
+		Formula:   rep.Formula,
+		Version:   rep.Version,
+		Tag:       rep.Tag,
+		Path:      bottlePath,
+		Sha256:    rep.Sha256,
+		Tab:       tab,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: oci push:", err)
+		return 1
+	}
+
+	rep.OCIRepository = res.Repository
+	rep.OCIManifestDigest = res.ManifestDigest
+	rep.OCIBlobURL = res.BlobURL
+
+	fmt.Println("pushed oci:", res.Repository+":"+res.Reference, res.ManifestDigest)
+	fmt.Printf("note: brew install from this registry needs HOMEBREW_ARTIFACT_DOMAIN=%s HOMEBREW_ARTIFACT_DOMAIN_NO_FALLBACK=1\n", strings.TrimRight(registry, "/"))
+	return 0
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gov-brew-bottle-creation/internal/fsutil"
+	"gov-brew-bottle-creation/internal/nexus"
+)
+
+const (
+	progressTTYDelay = time.Second      // kleine Dateien ohne Fortschrittsanzeige
+	progressLogEvery = 10 * time.Second // Abstand der Logzeilen ohne TTY
+)
+
+// stderrTTY: stderr ist ein Terminal (sonst z.B. CI-Log oder serve-Runner)
+func stderrTTY() bool {
+	fi, err := os.Stderr.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// uploadProgress: auf einem TTY eine laufend überschriebene Zeile, sonst gedrosselte Logzeilen.
+func uploadProgress(w io.Writer, tty bool) func(nexus.Progress) {
+	var printed time.Duration // Elapsed der letzten Ausgabe
+	shown := false
+	return func(p nexus.Progress) {
+		if tty {
+			if p.Elapsed < progressTTYDelay {
+				return
+			}
+			_, _ = fmt.Fprintf(w, "\r\x1b[K%s", progressLine(p))
+			if p.Done {
+				_, _ = fmt.Fprintln(w)
+			}
+			return
+		}
+		switch {
+		case p.Done && !shown:
+			return
+		case !p.Done && p.Elapsed-printed < progressLogEvery:
+			return
+		}
+		printed, shown = p.Elapsed, true
+		_, _ = fmt.Fprintln(w, "progress:", progressLine(p))
+	}
+}
+
+func progressLine(p nexus.Progress) string {
+	s := fmt.Sprintf("%s %s", p.File, fsutil.HumanSize(p.Sent))
+	if pct := p.Percent(); pct >= 0 {
+		s += fmt.Sprintf(" / %s (%.0f%%)", fsutil.HumanSize(p.Total), pct)
+	}
+	s += fmt.Sprintf(", %s/s", fsutil.HumanSize(int64(p.Rate())))
+	switch {
+	case p.Done:
+		s += fmt.Sprintf(", done in %s", p.Elapsed.Round(100*time.Millisecond))
+	case p.ETA() > 0:
+		s += fmt.Sprintf(", ETA %s", p.ETA().Round(time.Second))
+	}
+	return s
+}
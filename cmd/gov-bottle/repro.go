@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gov-brew-bottle-creation/internal/bottle"
+	"gov-brew-bottle-creation/internal/diff"
+	"gov-brew-bottle-creation/internal/hash"
+	"gov-brew-bottle-creation/internal/junit"
+)
+
+// sourceDateEpoch: SOURCE_DATE_EPOCH (Unix-Sekunden), leer = 1970-01-01
+func sourceDateEpoch(s string) (time.Time, error) {
+	if s == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q (unix seconds)", s)
+	}
+	return time.Unix(n, 0).UTC(), nil
+}
+
+// normalizeBottle: reproduzierbar neu packen und ausgeben
+func normalizeBottle(bottlePath string, epoch time.Time) int {
+	if err := bottle.Normalize(bottlePath, epoch); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	fmt.Println("normalized:", bottlePath, "(mtime", epoch.Format(time.RFC3339)+")")
+	return 0
+}
+
+// reproCheck: zweiter Build in eigenem Workdir, normalisieren und mit first vergleichen.
+// Bei Unterschieden bleibt das zweite Workdir zur Analyse liegen.
+func reproCheck(ctx context.Context, jr *junit.Recorder, brewBin, ref, workdir, first string, epoch time.Time, env map[string]string) (bool, int) {
+	jr.Begin("repro check")
+	dir, err := os.MkdirTemp(workdir, "repro-")
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: failed to create temp workdir:", err)
+		return false, 1
+	}
+	identical := false
+	defer func() {
+		if identical {
+			_ = os.RemoveAll(dir)
+		}
+	}()
+
+	fmt.Println("repro-check: second build in", dir)
+	second, rc := brewBuild(ctx, jr, brewBin, ref, dir, env)
+	if rc != 0 {
+		return false, rc
+	}
+	if rc := normalizeBottle(second, epoch); rc != 0 {
+		return false, rc
+	}
+
+	sum1, err := hash.FileSHA256(first)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: sha256:", err)
+		return false, 1
+	}
+	sum2, err := hash.FileSHA256(second)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: sha256:", err)
+		return false, 1
+	}
+	if sum1 == sum2 {
+		identical = true
+		fmt.Println("repro-check: identical, sha256", sum1)
+		jr.Pass()
+		return true, 0
+	}
+
+	fmt.Printf("repro-check: bottles differ (sha256 %s vs %s)\n", sum1, sum2)
+	a, err := diff.Read(first, first)
+	if err == nil {
+		var b diff.Side
+		if b, err = diff.Read(second, second); err == nil {
+			err = diff.WriteText(os.Stdout, diff.Compare(a, b, 0.25))
+		}
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: repro-check diff:", err)
+	}
+	fmt.Println("repro-check: second build kept in", dir)
+	jr.Fail("bottles of two builds differ", sum1+" vs "+sum2)
+	return false, 0
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gov-brew-bottle-creation/internal/naming"
+	"gov-brew-bottle-creation/internal/report"
+	"gov-brew-bottle-creation/internal/state"
+)
+
+// checkpoint: Schritt-Zustand eines Refs im Workdir. Fehler beim Schreiben brechen den Run nicht ab.
+type checkpoint struct {
+	st     *state.State
+	resume bool
+}
+
+// openCheckpoint: mit --resume den bestehenden State übernehmen, sonst neu beginnen.
+func openCheckpoint(workdir string, rep report.BottleReport, resume bool) (*checkpoint, error) {
+	path := filepath.Join(workdir, naming.State(rep.Formula, rep.Version, rep.Tag))
+
+	st := state.New(path)
+	if resume {
+		var err error
+		if st, err = state.Load(path); err != nil {
+			return nil, err
+		}
+		if !st.Matches(rep.Ref, rep.Version, rep.Tag) {
+			fmt.Println("resume: no checkpoint for", rep.Ref, rep.Version, rep.Tag, "- starting over")
+			resume = false
+		}
+	}
+	if !resume {
+		st.Reset(rep.Ref, rep.Version, rep.Tag)
+		if err := st.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return &checkpoint{st: st, resume: resume}, nil
+}
+
+// done: mit --resume ein abgeschlossener Schritt, dessen Artefakte noch gültig sind
+func (c *checkpoint) done(step string) *state.Step {
+	if c == nil || !c.resume {
+		return nil
+	}
+	st, ok := c.st.Done(step)
+	if !ok {
+		return nil
+	}
+	return st
+}
+
+func (c *checkpoint) complete(step string, started, finished time.Time, arts ...state.Artifact) {
+	if c == nil {
+		return
+	}
+	if err := c.st.Complete(step, started.UTC(), finished.UTC(), arts...); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: checkpoint:", err)
+	}
+}
+
+// uploaded: Datei mit gleichem Inhalt wurde in einem früheren Run schon nach url hochgeladen
+func (c *checkpoint) uploaded(path, url string) bool {
+	return c != nil && c.resume && c.st.Has(state.StepUpload, path, url)
+}
+
+func (c *checkpoint) addUpload(path, url string) {
+	if c == nil {
+		return
+	}
+	if err := c.st.Add(state.StepUpload, state.Artifact{Path: path, URL: url}); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: checkpoint:", err)
+	}
+}
+
+func (c *checkpoint) finish(step string) {
+	if c == nil {
+		return
+	}
+	if err := c.st.Finish(step); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: checkpoint:", err)
+	}
+}
+
+// saveReport: letzten Report im State mitführen (für Felder, die ein übersprungener Schritt gesetzt hat)
+func (c *checkpoint) saveReport(rep report.BottleReport) {
+	if c == nil {
+		return
+	}
+	c.st.Report = &rep
+	if err := c.st.Save(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: checkpoint:", err)
+	}
+}
+
+// report: Report aus dem Checkpoint (nil ohne --resume)
+func (c *checkpoint) report() *report.BottleReport {
+	if c == nil || !c.resume {
+		return nil
+	}
+	return c.st.Report
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gov-brew-bottle-creation/internal/brew"
+	"gov-brew-bottle-creation/internal/naming"
+	"gov-brew-bottle-creation/internal/report"
+	"gov-brew-bottle-creation/internal/sbom"
+)
+
+// writeSBOM erzeugt CycloneDX + SPDX aus dem gebauten Bottle und verlinkt sie im Report.
+func writeSBOM(ctx context.Context, brewBin string, rep *report.BottleReport, bottlePath, workdir, nexusBase string) int {
+	meta := sbom.Meta{
+		Formula:      rep.Formula,
+		Version:      rep.Version,
+		Tag:          rep.Tag,
+		BottleFile:   rep.BottleFile,
+		BottleSha256: rep.Sha256,
+		DownloadURL:  rep.NexusURLBottle,
+	}
+
+	// declared dependencies + Lizenz aus brew info (Fehler nur loggen)
+	fi, err := brew.Client{BrewPath: brewBin}.FormulaInfo(ctx, rep.Ref)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warn: sbom: brew info:", err)
+	} else {
+		meta.Description = fi.Desc
+		meta.Homepage = fi.Homepage
+		meta.DeclaredLicense = fi.License
+		for _, d := range fi.Dependencies {
+			meta.Dependencies = append(meta.Dependencies, sbom.Dependency{Name: d, Kind: "runtime"})
+		}
+		for _, d := range fi.BuildDependencies {
+			meta.Dependencies = append(meta.Dependencies, sbom.Dependency{Name: d, Kind: "build"})
+		}
+	}
+
+	doc, err := sbom.Generate(bottlePath, meta)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: sbom:", err)
+		return 1
+	}
+
+	cdxName := naming.SBOMCycloneDX(rep.Formula, rep.Version, rep.Tag)
+	spdxName := naming.SBOMSPDX(rep.Formula, rep.Version, rep.Tag)
+
+	if err := sbom.WriteCycloneDX(filepath.Join(workdir, cdxName), doc); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if err := sbom.WriteSPDX(filepath.Join(workdir, spdxName), doc); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	rep.SBOMCycloneDX = cdxName
+	rep.SBOMSPDX = spdxName
+	rep.NexusURLSBOMCycloneDX = joinURL(nexusBase, cdxName)
+	rep.NexusURLSBOMSPDX = joinURL(nexusBase, spdxName)
+
+	fmt.Println("wrote:", filepath.Join(workdir, cdxName))
+	fmt.Println("wrote:", filepath.Join(workdir, spdxName))
+	return 0
+}
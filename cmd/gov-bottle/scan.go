@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"gov-brew-bottle-creation/internal/cli"
+	"gov-brew-bottle-creation/internal/config"
+	"gov-brew-bottle-creation/internal/nexus"
+	"gov-brew-bottle-creation/internal/scan"
+)
+
+// runScan: Bottle-Abdeckung aller Formulae im Tap (formula × tag).
+func runScan(ctx context.Context, envCfg config.Config, args []string) int {
+	cfg, err := cli.ParseScanFlags(args)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	opts := scan.Options{
+		TapWorkdir: firstNonEmpty(cfg.TapWorkdir, envCfg.TapWorkdir),
+		Workdir:    firstNonEmpty(cfg.WorkDir, envCfg.DefaultWorkdir),
+		Tags:       cfg.Tags,
+	}
+	if len(opts.Tags) == 0 && envCfg.DefaultTag != "" {
+		opts.Tags = []string{envCfg.DefaultTag}
+	}
+
+	if cfg.Nexus {
+		base := firstNonEmpty(cfg.NexusBase, envCfg.NexusBaseURL)
+		if base == "" {
+			_, _ = fmt.Fprintln(os.Stderr, "error: missing nexus base. Set --nexus-base or NEXUS_BASE_URL in .env")
+			return 2
+		}
+		assets, err := nexus.Uploader{Client: httpClient}.List(ctx, base, envCfg.NexusUser, envCfg.NexusPass)
+		if err != nil {
+			return fail("nexus listing", err)
+		}
+		opts.Nexus = assets
+	}
+
+	res, err := scan.Run(opts)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: scan:", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if cfg.Out != "" {
+		f, err := os.Create(cfg.Out)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error: create output:", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch cfg.Format {
+	case "json":
+		err = scan.WriteJSON(w, res)
+	case "markdown":
+		err = scan.WriteMarkdown(w, res)
+	default:
+		err = scan.WriteTable(w, res)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: write output:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"gov-brew-bottle-creation/internal/cli"
+	"gov-brew-bottle-creation/internal/config"
+	"gov-brew-bottle-creation/internal/formula"
+	"gov-brew-bottle-creation/internal/metrics"
+	"gov-brew-bottle-creation/internal/service"
+	"gov-brew-bottle-creation/internal/webhook"
+)
+
+// Step -> CLI-Flag für den Pipeline-Subprozess
+var stepFlags = map[string]string{
+	"dry-run":        "--dry-run",
+	"build":          "--build-bottle",
+	"upload":         "--upload",
+	"update-formula": "--update-formula",
+	"sign":           "--sign",
+	"sbom":           "--sbom",
+	"provenance":     "--provenance",
+	"oci-push":       "--oci-push",
+	"force-build":    "--force-build",
+}
+
+// runServe: gov-bottle serve – REST API mit persistenter Job-Queue.
+func runServe(envCfg config.Config, args []string) int {
+	cfg, err := cli.ParseServeFlags(args)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	stateDir := firstNonEmpty(cfg.StateDir, envCfg.ServeStateDir)
+	if stateDir == "" {
+		stateDir = filepath.Join(envCfg.DefaultWorkdir, ".serve")
+	}
+	store, err := service.OpenStore(stateDir)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: resolve executable:", err)
+		return 1
+	}
+
+	svc := &service.Service{
+		Store:         store,
+		Run:           pipelineRunner(self, envCfg),
+		DefaultPrefix: envCfg.HomebrewPrefix,
+		Prefixes:      envCfg.ServePrefixes,
+		DefaultTag:    envCfg.DefaultTag,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// ohne Token nur lokal erreichbar: die API startet Builds
+	listen := firstNonEmpty(cfg.Listen, envCfg.ServeListen)
+	token := firstNonEmpty(cfg.Token, envCfg.ServeToken)
+	if token == "" && !loopback(listen) {
+		_, _ = fmt.Fprintf(os.Stderr, "error: refusing to serve on %s without SERVE_TOKEN (or --token)\n", listen)
+		_, _ = fmt.Fprintln(os.Stderr, "hint: set SERVE_TOKEN or listen on 127.0.0.1")
+		return 2
+	}
+
+	svc.Start(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", svc.Handler(token))
+	mux.Handle("GET /metrics", metrics.Default.Handler())
+
+	// Push-Webhook (eigene HMAC-Prüfung statt Bearer-Token)
+	if envCfg.WebhookSecret != "" {
+		for _, st := range envCfg.WebhookSteps {
+			if !service.KnownSteps[st] {
+				_, _ = fmt.Fprintf(os.Stderr, "error: WEBHOOK_STEPS: unknown step %q\n", st)
+				return 2
+			}
+		}
+		mux.Handle("POST /webhooks/push", &webhook.Handler{
+			Secret:     envCfg.WebhookSecret,
+			TapWorkdir: firstNonEmpty(cfg.TapWorkdir, envCfg.TapWorkdir),
+			Tap:        envCfg.TapName,
+			Branch:     envCfg.TapGitBranch,
+			Steps:      envCfg.WebhookSteps,
+			Submit:     svc.Submit,
+		})
+	} else {
+		fmt.Println("note: WEBHOOK_SECRET not set, /webhooks/push disabled")
+	}
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	fmt.Println("serving on", srv.Addr, "state:", stateDir)
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
+			_, _ = fmt.Fprintln(os.Stderr, "error: serve:", err)
+			svc.Stop()
+			return 1
+		}
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(shutdownCtx)
+	svc.Stop()
+	return 0
+}
+
+// loopback: addr lauscht nur auf localhost/127.0.0.1/::1 (":8080" heisst alle Interfaces)
+func loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// pipelineRunner startet für jeden Ref dieses Binary als Subprozess mit den passenden Flags.
+func pipelineRunner(self string, envCfg config.Config) service.Runner {
+	return func(ctx context.Context, job service.Job, ref string, log io.Writer, reportPath string) (int, error) {
+		// Metriken des Subprozesses landen neben dem Report und werden danach übernommen
+		metricsPath := strings.TrimSuffix(reportPath, ".json") + ".prom"
+		defer func() {
+			if err := metrics.Default.MergeFile(metricsPath); err != nil {
+				_, _ = fmt.Fprintln(log, "warn: metrics:", err)
+			}
+			_ = os.Remove(metricsPath)
+		}()
+
+		// Jobs aus einer älteren Queue wurden beim Submit evtl. noch nicht geprüft
+		if _, _, err := formula.ParseRef(ref); err != nil {
+			return -1, err
+		}
+		if p := job.BrewPrefix; p != "" && p != envCfg.HomebrewPrefix && !slices.Contains(envCfg.ServePrefixes, p) {
+			return -1, fmt.Errorf("brew_prefix %q not allowed (see SERVE_BREW_PREFIXES)", p)
+		}
+
+		args := []string{"--ref", ref, "--report-file", reportPath, "--metrics-file", metricsPath}
+		if job.Tag != "" {
+			args = append(args, "--tag", job.Tag)
+		}
+		// Worker anderer Prefixe teilen sich dist/: auf den Workdir-Lock warten statt abbrechen
+		if os.Getenv("LOCK_TIMEOUT") == "" {
+			args = append(args, "--lock-timeout", "-1")
+		}
+		for _, st := range job.Steps {
+			args = append(args, stepFlags[st])
+		}
+
+		cmd := exec.CommandContext(ctx, self, args...)
+		cmd.Stdout = log
+		cmd.Stderr = log
+		cmd.Env = os.Environ()
+		if job.BrewPrefix != "" && job.BrewPrefix != envCfg.HomebrewPrefix {
+			cmd.Env = append(cmd.Env,
+				"HOMEBREW_PREFIX="+job.BrewPrefix,
+				"BREW_BIN="+filepath.Join(job.BrewPrefix, "bin", "brew"),
+			)
+		}
+		// brew sauber beenden lassen statt SIGKILL
+		cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+		cmd.WaitDelay = 30 * time.Second
+
+		_, _ = fmt.Fprintln(log, "$", self, args)
+		err := cmd.Run()
+		if err == nil {
+			return 0, nil
+		}
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && ctx.Err() == nil {
+			return ee.ExitCode(), nil
+		}
+		return -1, err
+	}
+}
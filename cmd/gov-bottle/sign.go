@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/cli"
+	"gov-brew-bottle-creation/internal/config"
+	"gov-brew-bottle-creation/internal/sign"
+)
+
+// runSign: gov-bottle sign [--key <spec>] <file>...
+func runSign(ctx context.Context, envCfg config.Config, args []string) int {
+	cfg, err := cli.ParseSignFlags(args)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	signer, rc := loadSigner(ctx, firstNonEmpty(cfg.Key, envCfg.SignKey))
+	if rc != 0 {
+		return rc
+	}
+
+	for _, f := range cfg.Files {
+		sigPath, err := sign.SignFile(ctx, signer, f)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		fmt.Println("wrote:", sigPath)
+	}
+	return 0
+}
+
+// runVerifySignature: gov-bottle verify-signature [--trusted-keys <file>] <file>...
+func runVerifySignature(envCfg config.Config, args []string) int {
+	cfg, err := cli.ParseVerifySignatureFlags(args)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	trustedPath := firstNonEmpty(cfg.TrustedKeys, envCfg.TrustedKeys)
+	if trustedPath == "" {
+		_, _ = fmt.Fprintln(os.Stderr, "error: missing trusted keys. Set --trusted-keys or TRUSTED_KEYS in .env")
+		return 2
+	}
+	keys, err := sign.LoadTrustedKeys(trustedPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	rc := 0
+	for _, f := range cfg.Files {
+		f = strings.TrimSuffix(f, sign.Suffix)
+		keyID, err := sign.VerifyFile(keys, f, f+sign.Suffix)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "FAIL %s: %v\n", f, err)
+			rc = 1
+			continue
+		}
+		fmt.Printf("OK   %s (key %s)\n", f, keyID)
+	}
+	return rc
+}
+
+func loadSigner(ctx context.Context, spec string) (sign.Signer, int) {
+	if spec == "" {
+		_, _ = fmt.Fprintln(os.Stderr, "error: missing signing key. Set --sign-key or SIGN_KEY in .env")
+		return nil, 2
+	}
+	s, err := sign.Load(ctx, spec)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: load signing key:", err)
+		return nil, 1
+	}
+	return s, 0
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"gov-brew-bottle-creation/internal/cli"
+	"gov-brew-bottle-creation/internal/config"
+	"gov-brew-bottle-creation/internal/fsutil"
+	"gov-brew-bottle-creation/internal/lock"
+	"gov-brew-bottle-creation/internal/naming"
+	"gov-brew-bottle-creation/internal/nexus"
+	"gov-brew-bottle-creation/internal/ratelimit"
+	"gov-brew-bottle-creation/internal/report"
+	"gov-brew-bottle-creation/internal/syncdir"
+)
+
+// runSync: gov-bottle sync – dist/ und Nexus abgleichen (fehlende hochladen, Konflikte melden, optional pullen).
+func runSync(envCfg config.Config, args []string) int {
+	cfg, err := cli.ParseSyncFlags(args)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	workdir := firstNonEmpty(cfg.WorkDir, envCfg.DefaultWorkdir)
+	base := firstNonEmpty(cfg.NexusBase, envCfg.NexusBaseURL)
+	if base == "" {
+		_, _ = fmt.Fprintln(os.Stderr, "error: missing nexus base. Set --nexus-base or NEXUS_BASE_URL in .env")
+		return 2
+	}
+	if !cfg.DryRun && (envCfg.NexusUser == "" || envCfg.NexusPass == "") {
+		_, _ = fmt.Fprintln(os.Stderr, "error: must specify NEXUS_USER and NEXUS_PASS")
+		return 2
+	}
+
+	rate, err := ratelimit.ParseRate(firstNonEmpty(cfg.Bandwidth, envCfg.UploadLimit))
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// --pull schreibt ins Workdir, Uploads lesen Reports, die ein Run gerade neu schreibt
+	if !cfg.DryRun {
+		lockOpt, err := lockOptions(envCfg.LockTimeout, envCfg.LockStaleAfter)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+			return 2
+		}
+		lk, rc := acquireLock(ctx, lock.WorkdirPath(workdir), "workdir "+workdir, lockOpt)
+		if rc != 0 {
+			return rc
+		}
+		defer releaseLock(lk)
+	}
+
+	// Limit gilt für alle Uploads zusammen, Downloads bleiben unbegrenzt
+	up := nexus.Uploader{Client: httpClient, Limit: ratelimit.New(rate)}
+	assets, err := up.List(ctx, base, envCfg.NexusUser, envCfg.NexusPass)
+	if err != nil {
+		return fail("nexus listing", err)
+	}
+
+	entries, err := syncdir.Compare(syncdir.Options{
+		Workdir: workdir,
+		Remote:  assets,
+		URLFor:  func(name string) string { return joinURL(base, name) },
+		Tag:     cfg.Tag,
+		Pull:    cfg.Pull,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: sync:", err)
+		return 1
+	}
+	blocked, err := blockInvalid(envCfg, workdir, entries)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error: validate:", err)
+		return 1
+	}
+
+	if !cfg.JSON {
+		for _, e := range entries {
+			switch e.Action {
+			case syncdir.ActionInSync:
+				continue
+			case syncdir.ActionConflict:
+				fmt.Printf("%-11s %s (%s: local %s, nexus %s)\n", e.Action, e.Name, e.Detail, short(e.LocalSha256), short(e.RemoteSha256))
+			case syncdir.ActionBlocked:
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s not uploaded: %s\n", e.Name, e.Detail)
+			default:
+				fmt.Printf("%-11s %s (%s)\n", e.Action, e.Name, fsutil.HumanSize(e.Size()))
+			}
+		}
+	}
+
+	var sum syncdir.Summary
+	if cfg.DryRun {
+		sum = syncdir.Count(entries)
+	} else {
+		tr := syncdir.Transfer{
+			Jobs: cfg.Jobs,
+			Put: func(ctx context.Context, url, path string) error {
+				// parallele Uploads: Logzeilen statt TTY-Zeile
+				_, err := putFile(ctx, up, url, path, envCfg.NexusUser, envCfg.NexusPass, "sync", false)
+				return err
+			},
+			Get: func(ctx context.Context, url, path string) error {
+				return up.Download(ctx, url, path, envCfg.NexusUser, envCfg.NexusPass)
+			},
+		}
+		sum = tr.Run(ctx, entries, func(e syncdir.Entry, err error) {
+			if cfg.JSON {
+				return
+			}
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s %s: %v\n", e.Action, e.Name, err)
+				return
+			}
+			fmt.Printf("done: %s %s\n", e.Action, e.Name)
+		})
+	}
+
+	if cfg.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(struct {
+			DryRun  bool            `json:"dry_run"`
+			Entries []syncdir.Entry `json:"entries"`
+			Summary syncdir.Summary `json:"summary"`
+		}{cfg.DryRun, entries, sum})
+	} else {
+		verb := "summary:"
+		if cfg.DryRun {
+			verb = "dry-run:"
+		}
+		fmt.Printf("%s %d uploaded, %d pulled (%s), %d in sync, %d remote only, %d conflicts, %d failed\n",
+			verb, sum.Uploaded, sum.Pulled, fsutil.HumanSize(sum.Bytes), sum.InSync, sum.Remote, sum.Conflicts, len(sum.Failed))
+	}
+
+	if blocked > 0 {
+		return exitInvalid
+	}
+	if len(sum.Failed) > 0 || sum.Conflicts > 0 {
+		return 1
+	}
+	return 0
+}
+
+// blockInvalid: Bottles vor dem Upload wie bei upload prüfen (Report passt inkl. sha256, validateBottle).
+// Ein ungültiges Bottle wird samt Report auf ActionBlocked gesetzt; Rückgabe: Anzahl blockierter Einträge.
+func blockInvalid(envCfg config.Config, workdir string, entries []syncdir.Entry) (int, error) {
+	reasons := map[string]string{} // naming.Base -> Grund
+	checked := map[string]bool{}
+	for _, e := range entries {
+		if e.Action != syncdir.ActionUpload {
+			continue
+		}
+		p, ok := naming.Parse(e.Name)
+		if !ok {
+			continue
+		}
+		base := naming.Base(p.Formula, p.Version, p.Tag)
+		if checked[base] {
+			continue
+		}
+		checked[base] = true
+
+		bottle := filepath.Join(workdir, naming.BottleTarGz(p.Formula, p.Version, p.Tag))
+		if _, err := os.Stat(bottle); err != nil {
+			continue // nur der Report liegt lokal
+		}
+		// gleiche Prüfung wie upload --existing: Report vorhanden, für dieses Bottle, sha256 stimmt
+		if _, err := fsutil.FindBottles(workdir, fsutil.Filter{Formulae: []string{p.Formula}, Version: p.Version, Tag: p.Tag}); err != nil {
+			reasons[base] = err.Error()
+			continue
+		}
+		findings, err := validateBottle(envCfg, bottle, p.Formula, p.Version, true)
+		if err != nil {
+			return 0, err
+		}
+		if n := report.Errors(findings); n > 0 {
+			reasons[base] = fmt.Sprintf("failed validation with %d error(s)", n)
+		}
+	}
+
+	n := 0
+	for i, e := range entries {
+		p, ok := naming.Parse(e.Name)
+		if !ok || e.Action != syncdir.ActionUpload {
+			continue
+		}
+		if reason, bad := reasons[naming.Base(p.Formula, p.Version, p.Tag)]; bad {
+			entries[i].Action, entries[i].Detail = syncdir.ActionBlocked, reason
+			n++
+		}
+	}
+	return n, nil
+}
+
+func short(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	if sha == "" {
+		return "-"
+	}
+	return sha
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"gov-brew-bottle-creation/internal/config"
+	"gov-brew-bottle-creation/internal/formula"
+	"gov-brew-bottle-creation/internal/fsutil"
+	"gov-brew-bottle-creation/internal/hash"
+	"gov-brew-bottle-creation/internal/junit"
+	"gov-brew-bottle-creation/internal/metrics"
+	"gov-brew-bottle-creation/internal/naming"
+	"gov-brew-bottle-creation/internal/nexus"
+	"gov-brew-bottle-creation/internal/ratelimit"
+	"gov-brew-bottle-creation/internal/report"
+	"gov-brew-bottle-creation/internal/sign"
+	"gov-brew-bottle-creation/internal/upload"
+)
+
+// uploader: Nexus-Uploads eines Runs (Zugangsdaten, Parallelität, gemeinsames Bandbreiten-Limit)
+type uploader struct {
+	up   nexus.Uploader
+	user string
+	pass string
+	jobs int
+
+	sha256File bool // <bottle>.sha256 schreiben und mit hochladen
+}
+
+// newUploader: jobs/limit aus Flags, sonst UPLOAD_JOBS / UPLOAD_BANDWIDTH_LIMIT
+func newUploader(user, pass string, jobs int, jobsEnv, limit string) (uploader, error) {
+	if jobs == 0 {
+		n, err := strconv.Atoi(jobsEnv)
+		if err != nil {
+			return uploader{}, fmt.Errorf("invalid UPLOAD_JOBS %q", jobsEnv)
+		}
+		jobs = n
+	}
+	if jobs < 1 {
+		return uploader{}, fmt.Errorf("upload jobs must be >= 1")
+	}
+	rate, err := ratelimit.ParseRate(limit)
+	if err != nil {
+		return uploader{}, err
+	}
+	return uploader{
+		up:   nexus.Uploader{Client: httpClient, Limit: ratelimit.New(rate)},
+		user: user,
+		pass: pass,
+		jobs: jobs,
+	}, nil
+}
+
+// run lädt die Batches hoch; mit Checkpoint werden bereits hochgeladene Dateien (gleicher Inhalt,
+// gleiche URL) übersprungen. uploaded (optional) bekommt den Abschluss jeder Datei.
+// Fehler werden pro Datei ausgegeben, das Ergebnis ist nil oder upload.Errors.
+func (u uploader) run(ctx context.Context, batches []upload.Batch, cp *checkpoint, uploaded func(upload.File, nexus.Progress)) error {
+	for i := range batches {
+		b := &batches[i]
+		if b.Primary != nil && cp.uploaded(b.Primary.Path, b.Primary.URL) {
+			fmt.Println("resume: already uploaded:", b.Primary.URL)
+			b.Primary = nil
+		}
+		files := b.Files[:0:0]
+		for _, f := range b.Files {
+			if cp.uploaded(f.Path, f.URL) {
+				fmt.Println("resume: already uploaded:", f.URL)
+				continue
+			}
+			files = append(files, f)
+		}
+		b.Files = files
+	}
+
+	// eine überschriebene TTY-Zeile geht nur bei einem Upload zur Zeit
+	tty := stderrTTY() && u.jobs == 1
+	var mu sync.Mutex
+	progress := map[string]nexus.Progress{}
+	var hints []string // einmal pro Fehlerart, nicht pro Datei
+
+	pool := upload.Pool{
+		Jobs: u.jobs,
+		Put: func(ctx context.Context, f upload.File) error {
+			kind := f.Kind
+			if kind == "" {
+				kind = "sidecar"
+			}
+			p, err := putFile(ctx, u.up, f.URL, f.Path, u.user, u.pass, kind, tty)
+			mu.Lock()
+			progress[f.Path] = p
+			mu.Unlock()
+			return err
+		},
+		Done: func(_ *upload.Batch, f upload.File, err error) {
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: upload %s: %v\n", f.URL, err)
+				if h := hint(err); h != "" && !slices.Contains(hints, h) {
+					hints = append(hints, h)
+				}
+				return
+			}
+			mu.Lock()
+			p := progress[f.Path]
+			mu.Unlock()
+			cp.addUpload(f.Path, f.URL)
+			fmt.Printf("upload to: %s (%s in %s, %s/s)\n", f.URL, fsutil.HumanSize(p.Sent), p.Elapsed.Round(time.Millisecond), fsutil.HumanSize(int64(p.Rate())))
+			if uploaded != nil {
+				uploaded(f, p)
+			}
+		},
+	}
+	errs := pool.Run(ctx, batches)
+	if len(errs) == 0 {
+		return nil
+	}
+	if errors.Is(errs, context.Canceled) {
+		_, _ = fmt.Fprintln(os.Stderr, "error: upload canceled")
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "error: %d of the uploads failed\n", len(errs))
+	for _, h := range hints {
+		_, _ = fmt.Fprintln(os.Stderr, "hint:", h)
+	}
+	return errs
+}
+
+// existingBatch: --nexus-upload für ein Bottle aus dem Workdir (Bottle, json und vorhandene Zusatzdateien)
+func existingBatch(b fsutil.Bottle, nexusBase string, withSum bool) upload.Batch {
+	dir := filepath.Dir(b.Path)
+	base := fmt.Sprintf("%s-%s.%s", b.Formula, b.Version, b.Tag)
+	bottleURL := joinURL(nexusBase, filepath.Base(b.Path))
+	jsonURL := joinURL(nexusBase, filepath.Base(b.JSONPath))
+
+	batch := upload.Batch{
+		Name:    base,
+		Primary: &upload.File{Path: b.Path, URL: bottleURL, Required: true, Kind: "bottle"},
+		Files: []upload.File{
+			{Path: b.JSONPath, URL: jsonURL, Required: true, Kind: "json"},
+			// Signaturen, SBOMs und Provenance mitnehmen, falls vorhanden
+			{Path: b.Path + sign.Suffix, URL: bottleURL + sign.Suffix},
+			{Path: b.JSONPath + sign.Suffix, URL: jsonURL + sign.Suffix},
+			{Path: filepath.Join(dir, base+naming.SBOMCycloneDXSuffix), URL: joinURL(nexusBase, base+naming.SBOMCycloneDXSuffix)},
+			{Path: filepath.Join(dir, base+naming.SBOMSPDXSuffix), URL: joinURL(nexusBase, base+naming.SBOMSPDXSuffix)},
+			{Path: filepath.Join(dir, base+naming.ProvenanceSuffix), URL: joinURL(nexusBase, base+naming.ProvenanceSuffix)},
+			{Path: filepath.Join(dir, base+naming.ProvenanceSuffix+sign.Suffix), URL: joinURL(nexusBase, base+naming.ProvenanceSuffix+sign.Suffix)},
+		},
+	}
+	if withSum {
+		batch.Files = append(batch.Files, upload.File{Path: b.Path + hash.Suffix, URL: bottleURL + hash.Suffix, Required: true})
+	}
+	return batch
+}
+
+// uploadExisting: alle gewählten Bottles gemeinsam über den Pool; Metrik und JUnit-Gruppe pro Bottle
+func uploadExisting(ctx context.Context, envCfg config.Config, u uploader, bottles []fsutil.Bottle, nexusBase string, jr *junit.Recorder) int {
+	// erst alle prüfen, damit nicht die Hälfte hochgeladen ist
+	invalid := 0
+	for _, b := range bottles {
+		jr.Group(filepath.Base(b.Path))
+		jr.Begin("validate")
+		findings, err := validateBottle(envCfg, b.Path, b.Report.Formula, b.Report.Version, true)
+		if err != nil {
+			jr.Fail(err.Error(), "")
+			_, _ = fmt.Fprintln(os.Stderr, "error: validate:", err)
+			return 1
+		}
+		if n := report.Errors(findings); n > 0 {
+			jr.Fail(fmt.Sprintf("%d validation error(s)", n), "")
+			_, _ = fmt.Fprintf(os.Stderr, "error: %s failed validation with %d error(s)\n", filepath.Base(b.Path), n)
+			invalid++
+			continue
+		}
+		jr.Pass()
+	}
+	if invalid > 0 {
+		for _, b := range bottles {
+			jr.Group(filepath.Base(b.Path))
+			jr.Skip("upload", fmt.Sprintf("%d bottle(s) invalid, nothing uploaded", invalid))
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "error: %d of %d bottle(s) invalid, nothing uploaded\n", invalid, len(bottles))
+		return exitInvalid
+	}
+
+	batches := make([]upload.Batch, len(bottles))
+	for i, b := range bottles {
+		if u.sha256File {
+			if _, err := hash.WriteSidecar(b.Path, b.Report.Sha256); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+				return 1
+			}
+		}
+		batches[i] = existingBatch(b, nexusBase, u.sha256File)
+	}
+	started := time.Now()
+	err := u.run(ctx, batches, nil, nil)
+	took := time.Since(started)
+
+	// Fehler pro Batch; ohne Zuordnung (z.B. abgebrochen) betrifft er alle
+	failed := map[string]string{}
+	var errs upload.Errors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			if failed[e.Batch] != "" {
+				failed[e.Batch] += "; "
+			}
+			failed[e.Batch] += e.Error()
+		}
+	} else if err != nil {
+		for _, b := range batches {
+			failed[b.Name] = err.Error()
+		}
+	}
+	for i, b := range bottles {
+		msg := failed[batches[i].Name]
+		result := "success"
+		if msg != "" {
+			result = "failure"
+		}
+		metrics.UploadsTotal.Inc(b.Formula, b.Tag, result)
+		jr.Group(filepath.Base(b.Path))
+		jr.Record("upload", took, msg)
+	}
+	return exitCode(err)
+}
+
+// refFormula: owner/tap/formula -> formula (ein blanker Name bleibt wie er ist)
+func refFormula(ref string) string {
+	if _, name, err := formula.ParseRef(ref); err == nil {
+		return name
+	}
+	return ref
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gov-brew-bottle-creation/internal/config"
+	"gov-brew-bottle-creation/internal/fsutil"
+	"gov-brew-bottle-creation/internal/report"
+	"gov-brew-bottle-creation/internal/validate"
+)
+
+// validateOptions: Grenzen aus BOTTLE_MIN_SIZE / BOTTLE_MAX_SIZE / BOTTLE_MAX_UNPACKED
+func validateOptions(envCfg config.Config, formula, version string) (validate.Options, error) {
+	opts := validate.Options{Formula: formula, Version: version}
+	var err error
+	if opts.MinSize, err = fsutil.ParseSize(envCfg.MinSize); err != nil {
+		return opts, fmt.Errorf("BOTTLE_MIN_SIZE: %w", err)
+	}
+	if opts.MaxSize, err = fsutil.ParseSize(envCfg.MaxSize); err != nil {
+		return opts, fmt.Errorf("BOTTLE_MAX_SIZE: %w", err)
+	}
+	if opts.MaxUnpacked, err = fsutil.ParseSize(envCfg.MaxUnpacked); err != nil {
+		return opts, fmt.Errorf("BOTTLE_MAX_UNPACKED: %w", err)
+	}
+	return opts, nil
+}
+
+// validateBottle prüft das Bottle und gibt die Findings aus; blocking: Fehler verhindern
+// Upload, Push bzw. Formula-Update (als error ausgeben), sonst nur Warnung.
+func validateBottle(envCfg config.Config, bottlePath, formula, version string, blocking bool) ([]report.Finding, error) {
+	opts, err := validateOptions(envCfg, formula, version)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := validate.Bottle(bottlePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range findings {
+		level := "warn"
+		if f.Severity == report.SeverityError && blocking {
+			level = "error"
+		}
+		where := ""
+		if f.Path != "" {
+			where = " " + f.Path
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "%s: validate %s%s: %s\n", level, f.Rule, where, f.Message)
+	}
+	return findings, nil
+}
@@ -0,0 +1,145 @@
+package bottle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type spooled struct {
+	hdr tar.Header
+	off int64 // Inhalt im Spool-File (nur TypeReg)
+}
+
+// Normalize packt das Bottle reproduzierbar neu: Einträge sortiert, mtime = epoch,
+// uid/gid 0 ohne Namen, keine PAX/xattrs, gzip-Header ohne Name und Zeit.
+// Dateiinhalte bleiben unverändert; ersetzt wird atomar über <path>.norm.
+func Normalize(bottlePath string, epoch time.Time) error {
+	spool, err := os.CreateTemp(filepath.Dir(bottlePath), ".spool-*")
+	if err != nil {
+		return fmt.Errorf("normalize: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	var entries []spooled
+	var off int64
+	err = Walk(bottlePath, func(hdr *tar.Header, r io.Reader) error {
+		e := spooled{hdr: *hdr, off: off}
+		if hdr.Typeflag == tar.TypeReg {
+			n, err := io.Copy(spool, r)
+			if err != nil {
+				return fmt.Errorf("spool %s: %w", hdr.Name, err)
+			}
+			off += n
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("normalize: %w", err)
+	}
+
+	// Eltern-Verzeichnisse sind Präfix ihrer Kinder und kommen so automatisch zuerst
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].hdr.Name < entries[j].hdr.Name })
+	fixHardlinks(entries)
+
+	tmp := bottlePath + ".norm"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("normalize: %w", err)
+	}
+	if err := writeNormalized(f, spool, entries, epoch.UTC().Truncate(time.Second)); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("normalize: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("normalize: %w", err)
+	}
+	if err := os.Rename(tmp, bottlePath); err != nil {
+		return fmt.Errorf("normalize: %w", err)
+	}
+	return nil
+}
+
+func writeNormalized(w io.Writer, spool io.ReaderAt, entries []spooled, mtime time.Time) error {
+	gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		h := &tar.Header{
+			Typeflag: e.hdr.Typeflag,
+			Name:     e.hdr.Name,
+			Linkname: e.hdr.Linkname,
+			Mode:     e.hdr.Mode & 07777,
+			ModTime:  mtime,
+			Devmajor: e.hdr.Devmajor,
+			Devminor: e.hdr.Devminor,
+		}
+		if h.Typeflag == tar.TypeReg {
+			h.Size = e.hdr.Size
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			return fmt.Errorf("write %s: %w", h.Name, err)
+		}
+		if h.Size > 0 {
+			if _, err := io.Copy(tw, io.NewSectionReader(spool, e.off, h.Size)); err != nil {
+				return fmt.Errorf("write %s: %w", h.Name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// fixHardlinks: nach dem Sortieren muss das Ziel eines Hardlinks vor dem Link stehen.
+// Der erste Eintrag einer Gruppe bekommt den Inhalt, die übrigen verweisen auf ihn.
+func fixHardlinks(entries []spooled) {
+	index := map[string]int{}
+	for i, e := range entries {
+		index[path.Clean(e.hdr.Name)] = i
+	}
+	first := map[int]int{} // Ziel -> erster Eintrag der Gruppe
+	for i := range entries {
+		e := &entries[i]
+		if e.hdr.Typeflag != tar.TypeLink {
+			continue
+		}
+		t, ok := index[path.Clean(e.hdr.Linkname)]
+		if !ok {
+			continue
+		}
+		// Typ nur beim ersten Link prüfen: danach ist das Ziel evtl. schon selbst ein Link
+		f, seen := first[t]
+		if !seen {
+			if entries[t].hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			f = min(i, t)
+			first[t] = f
+		}
+		if f == t {
+			continue
+		}
+		if i == f {
+			// dieser Link wird zur Datei, das bisherige Ziel zum Link
+			e.hdr.Typeflag, e.hdr.Linkname, e.hdr.Size = tar.TypeReg, "", entries[t].hdr.Size
+			e.off = entries[t].off
+			entries[t].hdr.Typeflag, entries[t].hdr.Linkname = tar.TypeLink, e.hdr.Name
+			continue
+		}
+		e.hdr.Linkname = entries[f].hdr.Name
+	}
+}
@@ -0,0 +1,191 @@
+package bottle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gov-brew-bottle-creation/internal/hash"
+)
+
+type entry struct {
+	hdr  tar.Header
+	body string
+}
+
+func file(name, body string) entry {
+	return entry{hdr: tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(body))}, body: body}
+}
+
+func dir(name string) entry {
+	return entry{hdr: tar.Header{Name: name, Typeflag: tar.TypeDir, Mode: 0o755}}
+}
+
+func hardlink(name, target string) entry {
+	return entry{hdr: tar.Header{Name: name, Typeflag: tar.TypeLink, Linkname: target, Mode: 0o644}}
+}
+
+// writeTarGz: Einträge in der gegebenen Reihenfolge; mtime/uid/gname wie von brew bzw. vom Build-User
+func writeTarGz(t *testing.T, p string, entries []entry, mtime time.Time, uid int, owner string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Name, zw.ModTime = filepath.Base(p), mtime
+	tw := tar.NewWriter(zw)
+	for _, e := range entries {
+		h := e.hdr
+		h.ModTime, h.Uid, h.Gid, h.Uname, h.Gname = mtime, uid, uid, owner, "staff"
+		if err := tw.WriteHeader(&h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, e.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type readEntry struct {
+	typeflag byte
+	linkname string
+	body     string
+	mtime    time.Time
+	uid      int
+	uname    string
+}
+
+func readTarGz(t *testing.T, p string) ([]string, map[string]readEntry) {
+	t.Helper()
+	var names []string
+	out := map[string]readEntry{}
+	err := Walk(p, func(hdr *tar.Header, r io.Reader) error {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		names = append(names, hdr.Name)
+		out[hdr.Name] = readEntry{hdr.Typeflag, hdr.Linkname, string(b), hdr.ModTime, hdr.Uid, hdr.Uname}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read %s: %v", p, err)
+	}
+	return names, out
+}
+
+func TestNormalizeReordersHardlinks(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "gov-srt--1.5.4.arm64_sonoma.bottle.tar.gz")
+	// brew packt in Verzeichnis-Reihenfolge: das Ziel (z/) steht vor den Links, nach dem Sortieren dahinter
+	writeTarGz(t, p, []entry{
+		dir("gov-srt/"),
+		dir("gov-srt/1.5.4/"),
+		file("gov-srt/1.5.4/z/srt-live-transmit", "binary"),
+		hardlink("gov-srt/1.5.4/m/srt-file-transmit", "gov-srt/1.5.4/z/srt-live-transmit"),
+		hardlink("gov-srt/1.5.4/a/srt-tunnel", "gov-srt/1.5.4/z/srt-live-transmit"),
+	}, time.Now(), 501, "builder")
+
+	epoch := time.Unix(1700000000, 0)
+	if err := Normalize(p, epoch); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+
+	names, got := readTarGz(t, p)
+	want := []string{
+		"gov-srt/", "gov-srt/1.5.4/",
+		"gov-srt/1.5.4/a/srt-tunnel",
+		"gov-srt/1.5.4/m/srt-file-transmit",
+		"gov-srt/1.5.4/z/srt-live-transmit",
+	}
+	if len(names) != len(want) {
+		t.Fatalf("entries = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("entries = %v, want %v", names, want)
+		}
+	}
+
+	// erster Eintrag der Gruppe trägt den Inhalt, alle anderen verweisen auf ihn
+	if e := got["gov-srt/1.5.4/a/srt-tunnel"]; e.typeflag != tar.TypeReg || e.body != "binary" {
+		t.Fatalf("first of group = %+v, want regular file with content", e)
+	}
+	for _, n := range []string{"gov-srt/1.5.4/m/srt-file-transmit", "gov-srt/1.5.4/z/srt-live-transmit"} {
+		if e := got[n]; e.typeflag != tar.TypeLink || e.linkname != "gov-srt/1.5.4/a/srt-tunnel" {
+			t.Fatalf("%s = %+v, want hard link to a/srt-tunnel", n, e)
+		}
+	}
+	for n, e := range got {
+		if !e.mtime.Equal(epoch) || e.uid != 0 || e.uname != "" {
+			t.Fatalf("%s: mtime %v uid %d uname %q not normalized", n, e.mtime, e.uid, e.uname)
+		}
+	}
+}
+
+func TestNormalizeIsReproducible(t *testing.T) {
+	entries := []entry{
+		dir("gov-srt/"),
+		dir("gov-srt/1.5.4/"),
+		dir("gov-srt/1.5.4/lib/"),
+		file("gov-srt/1.5.4/INSTALL_RECEIPT.json", `{"source":{"versions":{"stable":"1.5.4"}}}`),
+		file("gov-srt/1.5.4/lib/libsrt.1.5.dylib", "library"),
+		hardlink("gov-srt/1.5.4/lib/libsrt.dylib", "gov-srt/1.5.4/lib/libsrt.1.5.dylib"),
+		{hdr: tar.Header{Name: "gov-srt/1.5.4/lib/libsrt.1.dylib", Typeflag: tar.TypeSymlink, Linkname: "libsrt.1.5.dylib", Mode: 0o777}},
+	}
+	// zweiter Build: andere Reihenfolge (Hardlink-Ziel zuletzt), andere Zeit und anderer User
+	reordered := []entry{
+		entries[0], entries[1], entries[3], entries[2], entries[6],
+		file("gov-srt/1.5.4/lib/libsrt.dylib", "library"),
+		hardlink("gov-srt/1.5.4/lib/libsrt.1.5.dylib", "gov-srt/1.5.4/lib/libsrt.dylib"),
+	}
+
+	tmp := t.TempDir()
+	a := filepath.Join(tmp, "a.bottle.tar.gz")
+	b := filepath.Join(tmp, "b.bottle.tar.gz")
+	writeTarGz(t, a, entries, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), 501, "builder")
+	writeTarGz(t, b, reordered, time.Date(2026, 6, 7, 8, 9, 10, 0, time.UTC), 0, "root")
+
+	shaA, _ := hash.FileSHA256(a)
+	shaB, _ := hash.FileSHA256(b)
+	if shaA == shaB {
+		t.Fatal("inputs already identical, test proves nothing")
+	}
+
+	epoch := time.Unix(1700000000, 0)
+	for _, p := range []string{a, b} {
+		if err := Normalize(p, epoch); err != nil {
+			t.Fatalf("Normalize %s: %v", p, err)
+		}
+	}
+	shaA, err := hash.FileSHA256(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shaB, err = hash.FileSHA256(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shaA != shaB {
+		t.Fatalf("normalized bottles differ: %s vs %s", shaA, shaB)
+	}
+
+	// zweimal normalisieren ändert nichts
+	if err := Normalize(a, epoch); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := hash.FileSHA256(a); again != shaA {
+		t.Fatalf("Normalize is not idempotent: %s -> %s", shaA, again)
+	}
+}
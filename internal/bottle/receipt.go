@@ -0,0 +1,73 @@
+package bottle
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Receipt ist der Teil von INSTALL_RECEIPT.json (Homebrew "tab"), den wir auswerten.
+type Receipt struct {
+	HomebrewVersion string `json:"homebrew_version"`
+	Arch            string `json:"arch"`
+	Source          struct {
+		Tap        string `json:"tap"`
+		TapGitHead string `json:"tap_git_head"`
+		Path       string `json:"path"`
+		Versions   struct {
+			Stable string `json:"stable"`
+		} `json:"versions"`
+	} `json:"source"`
+	RuntimeDependencies []struct {
+		FullName   string `json:"full_name"`
+		Version    string `json:"version"`
+		Revision   int    `json:"revision"`
+		PkgVersion string `json:"pkg_version"`
+	} `json:"runtime_dependencies"`
+	BuiltOn struct {
+		OS        string `json:"os"`
+		OSVersion string `json:"os_version"`
+		Xcode     string `json:"xcode"`
+	} `json:"built_on"`
+}
+
+// ReadReceipt liefert den Inhalt von <formula>/<version>/INSTALL_RECEIPT.json (Homebrew "tab").
+func ReadReceipt(bottlePath string) ([]byte, error) {
+	var out []byte
+	err := Walk(bottlePath, func(hdr *tar.Header, r io.Reader) error {
+		if hdr.Typeflag != tar.TypeReg || !isReceipt(hdr.Name) {
+			return nil
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("read receipt: %w", err)
+		}
+		out = b
+		return ErrStop
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, fmt.Errorf("INSTALL_RECEIPT.json not found in %s", bottlePath)
+	}
+	return out, nil
+}
+
+// ParseReceipt liest INSTALL_RECEIPT.json aus dem Bottle und parst es.
+func ParseReceipt(bottlePath string) (Receipt, []byte, error) {
+	raw, err := ReadReceipt(bottlePath)
+	if err != nil {
+		return Receipt{}, nil, err
+	}
+	var rc Receipt
+	if err := json.Unmarshal(raw, &rc); err != nil {
+		return Receipt{}, raw, fmt.Errorf("parse INSTALL_RECEIPT.json: %w", err)
+	}
+	return rc, raw, nil
+}
+
+func isReceipt(name string) bool {
+	return StripPrefix(name) == "INSTALL_RECEIPT.json"
+}
@@ -0,0 +1,66 @@
+package bottle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ErrStop kann von einem WalkFunc zurückgegeben werden, um Walk ohne Fehler abzubrechen.
+var ErrStop = errors.New("stop walk")
+
+// WalkFunc wird für jeden Eintrag im Bottle aufgerufen. r liefert den Inhalt (nur bei regulären Dateien sinnvoll).
+type WalkFunc func(hdr *tar.Header, r io.Reader) error
+
+// Walk streamt ein .bottle.tar.gz und ruft fn für jeden Eintrag auf.
+func Walk(bottlePath string, fn WalkFunc) error {
+	f, err := os.Open(bottlePath)
+	if err != nil {
+		return fmt.Errorf("open bottle: %w", err)
+	}
+	defer f.Close()
+
+	return WalkReader(f, fn)
+}
+
+// WalkReader wie Walk, aber auf einem beliebigen (gzip) Stream.
+func WalkReader(r io.Reader, fn WalkFunc) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("gzip: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tar: %w", err)
+		}
+		if err := fn(hdr, tr); err != nil {
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// StripPrefix entfernt "<formula>/<version>/" vom Pfad im Bottle.
+// Liefert "" für die beiden Top-Level Verzeichnisse selbst.
+func StripPrefix(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"strings"
 )
 
 type Client struct {
@@ -13,20 +14,75 @@ type Client struct {
 }
 
 type infoV2 struct {
-	Formulae []struct {
-		Versions struct {
-			Stable string `json:"stable"`
-		} `json:"versions"`
-	} `json:"formulae"`
+	Formulae []infoFormula `json:"formulae"`
+}
+
+type infoFormula struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Desc     string `json:"desc"`
+	License  string `json:"license"`
+	Homepage string `json:"homepage"`
+	Versions struct {
+		Stable string `json:"stable"`
+	} `json:"versions"`
+	Revision           int      `json:"revision"`
+	Dependencies       []string `json:"dependencies"`
+	BuildDependencies  []string `json:"build_dependencies"`
+	RubySourceChecksum struct {
+		Sha256 string `json:"sha256"`
+	} `json:"ruby_source_checksum"`
+}
+
+// FormulaInfo ist der Teil von "brew info --json=v2", den wir weiterverwenden.
+type FormulaInfo struct {
+	Name     string
+	FullName string
+	Desc     string
+	License  string
+	Homepage string
+	Version  string
+	Revision int
+
+	Dependencies      []string
+	BuildDependencies []string
+
+	SourceSha256 string // sha256 der Formula .rb (ruby_source_checksum)
+}
+
+// PkgVersion wie Homebrew: version bzw. version_revision
+func (f FormulaInfo) PkgVersion() string {
+	if f.Revision > 0 {
+		return fmt.Sprintf("%s_%d", f.Version, f.Revision)
+	}
+	return f.Version
 }
 
 func (c Client) FormulaVersion(ctx context.Context, ref string) (string, error) {
-	brew := c.BrewPath
-	if brew == "" {
-		brew = "brew"
+	fi, err := c.FormulaInfo(ctx, ref)
+	if err != nil {
+		return "", err
 	}
+	return fi.Version, nil
+}
 
-	cmd := exec.CommandContext(ctx, brew, "info", "--json=v2", ref)
+func (c Client) FormulaInfo(ctx context.Context, ref string) (FormulaInfo, error) {
+	infos, err := c.FormulaInfos(ctx, ref)
+	if err != nil {
+		return FormulaInfo{}, err
+	}
+	if len(infos) == 0 || infos[0].Version == "" {
+		return FormulaInfo{}, fmt.Errorf("no stable version found for %q", ref)
+	}
+	return infos[0], nil
+}
+
+// FormulaInfos: ein "brew info --json=v2" Aufruf für mehrere Formulae.
+func (c Client) FormulaInfos(ctx context.Context, refs ...string) ([]FormulaInfo, error) {
+	brew := c.bin()
+
+	args := append([]string{"info", "--json=v2"}, refs...)
+	cmd := exec.CommandContext(ctx, brew, args...)
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -34,7 +90,7 @@ func (c Client) FormulaVersion(ctx context.Context, ref string) (string, error)
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("brew info failed: %w (stderr=%q)", err, stderr.String())
+		return nil, newExitError(brew, args, stderr.String(), err)
 	}
 
 	raw := stdout.Bytes()
@@ -42,18 +98,59 @@ func (c Client) FormulaVersion(ctx context.Context, ref string) (string, error)
 	// gov-brew prints a banner before the JSON. Find the first '{' and parse from there.
 	i := bytes.IndexByte(raw, '{')
 	if i < 0 {
-		return "", fmt.Errorf("no JSON found in output (stdout=%q stderr=%q)", stdout.String(), stderr.String())
+		return nil, fmt.Errorf("no JSON found in output (stdout=%q stderr=%q)", stdout.String(), stderr.String())
 	}
 	raw = raw[i:]
 
 	var parsed infoV2
 	if err := json.Unmarshal(raw, &parsed); err != nil {
-		return "", fmt.Errorf("parse brew info json: %w", err)
+		return nil, fmt.Errorf("parse brew info json: %w", err)
+	}
+
+	out := make([]FormulaInfo, 0, len(parsed.Formulae))
+	for _, f := range parsed.Formulae {
+		out = append(out, FormulaInfo{
+			Name:              f.Name,
+			FullName:          f.FullName,
+			Desc:              f.Desc,
+			License:           f.License,
+			Homepage:          f.Homepage,
+			Version:           f.Versions.Stable,
+			Revision:          f.Revision,
+			Dependencies:      f.Dependencies,
+			BuildDependencies: f.BuildDependencies,
+			SourceSha256:      f.RubySourceChecksum.Sha256,
+		})
+	}
+	return out, nil
+}
+
+// ResolvedDependencies liefert die rekursiven Runtime-Dependencies mit ihrer aktuellen pkg_version.
+func (c Client) ResolvedDependencies(ctx context.Context, ref string) (map[string]string, error) {
+	out, _, _, err := Run(ctx, c.bin(), []string{"deps", "--full-name", ref}, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	names := strings.Fields(out)
+	deps := map[string]string{}
+	if len(names) == 0 {
+		return deps, nil
 	}
 
-	if len(parsed.Formulae) == 0 || parsed.Formulae[0].Versions.Stable == "" {
-		return "", fmt.Errorf("no stable version found for %q", ref)
+	infos, err := c.FormulaInfos(ctx, names...)
+	if err != nil {
+		return nil, err
 	}
+	for _, fi := range infos {
+		deps[fi.FullName] = fi.PkgVersion()
+	}
+	return deps, nil
+}
 
-	return parsed.Formulae[0].Versions.Stable, nil
+func (c Client) bin() string {
+	if c.BrewPath == "" {
+		return "brew"
+	}
+	return c.BrewPath
 }
@@ -0,0 +1,55 @@
+package brew
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// stderrTailLines: so viele Zeilen stderr behält ExitError
+const stderrTailLines = 20
+
+// ExitError: Programm (meist brew) ist nicht mit Exit-Code 0 beendet worden.
+type ExitError struct {
+	Bin      string
+	Args     []string
+	ExitCode int    // -1: nicht gestartet oder durch Signal beendet
+	Stderr   string // letzte Zeilen von stderr
+	Err      error
+}
+
+func newExitError(bin string, args []string, stderr string, err error) *ExitError {
+	code := -1
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		code = ee.ExitCode()
+	}
+	return &ExitError{Bin: bin, Args: args, ExitCode: code, Stderr: tail(stderr, stderrTailLines), Err: err}
+}
+
+func (e *ExitError) Error() string {
+	s := fmt.Sprintf("%s %s failed: exit %d: %v", filepath.Base(e.Bin), strings.Join(e.Args, " "), e.ExitCode, e.Err)
+	if e.Stderr != "" {
+		// letzte Zeile reicht für die Meldung, der Rest steht in Stderr
+		s += fmt.Sprintf(" (stderr: %s)", tail(e.Stderr, 1))
+	}
+	return s
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// NotFound: Programm nicht gefunden (BREW_BIN falsch)
+func (e *ExitError) NotFound() bool {
+	return errors.Is(e.Err, exec.ErrNotFound) || errors.Is(e.Err, fs.ErrNotExist) || e.ExitCode == 127
+}
+
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
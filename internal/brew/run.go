@@ -3,10 +3,10 @@ package brew
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"os/exec"
 )
 
+// Run führt bin aus; bei Fehler ist err ein *ExitError (Exit-Code und stderr auch einzeln zurück).
 func Run(ctx context.Context, bin string, args []string, dir string, env map[string]string) (string, string, int, error) {
 	cmd := exec.CommandContext(ctx, bin, args...)
 	if dir != "" {
@@ -26,14 +26,9 @@ func Run(ctx context.Context, bin string, args []string, dir string, env map[str
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
-	if err != nil {
-		// Exit code
-		exit := 1
-		if ee, ok := err.(*exec.ExitError); ok {
-			exit = ee.ExitCode()
-		}
-		return stdout.String(), stderr.String(), exit, fmt.Errorf("run failed: %w (cmd=%q)", err, cmd.String())
+	if err := cmd.Run(); err != nil {
+		ee := newExitError(bin, args, stderr.String(), err)
+		return stdout.String(), stderr.String(), ee.ExitCode, ee
 	}
 	return stdout.String(), stderr.String(), 0, nil
 }
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/hash"
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// Inputs bestimmen, ob ein Bottle neu gebaut werden muss.
+type Inputs struct {
+	FormulaSha256 string // Inhalt der .rb
+	Version       string
+	Revision      int
+	Tag           string
+	Dependencies  map[string]string // full_name -> pkg_version (rekursiv, runtime)
+}
+
+// Key: sha256 über eine kanonische Textform der Inputs.
+func Key(in Inputs) string {
+	var b strings.Builder
+	b.WriteString("gov-bottle-cache-v1\n")
+	fmt.Fprintf(&b, "formula_sha256=%s\n", in.FormulaSha256)
+	fmt.Fprintf(&b, "version=%s\n", in.Version)
+	fmt.Fprintf(&b, "revision=%d\n", in.Revision)
+	fmt.Fprintf(&b, "tag=%s\n", in.Tag)
+
+	names := make([]string, 0, len(in.Dependencies))
+	for n := range in.Dependencies {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(&b, "dep=%s@%s\n", n, in.Dependencies[n])
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Match: passt ein Report zum Key und hat er ein Bottle mit sha256?
+func Match(rep *report.BottleReport, key string) bool {
+	return rep != nil && key != "" && rep.CacheKey == key && rep.Sha256 != ""
+}
+
+// ParseReport parst einen .bottle.json Report.
+func ParseReport(b []byte) (*report.BottleReport, error) {
+	var rep report.BottleReport
+	if err := json.Unmarshal(b, &rep); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	return &rep, nil
+}
+
+// FromDist sucht in workdir einen Report mit gleichem Key, dessen Bottle noch
+// vorhanden ist und die sha256 aus dem Report hat. Bei Miss: nil, "", nil.
+func FromDist(workdir, jsonName, key string) (*report.BottleReport, string, error) {
+	b, err := os.ReadFile(filepath.Join(workdir, jsonName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", nil
+		}
+		return nil, "", fmt.Errorf("read report: %w", err)
+	}
+	rep, err := ParseReport(b)
+	if err != nil {
+		return nil, "", err
+	}
+	if !Match(rep, key) {
+		return nil, "", nil
+	}
+
+	bottlePath := filepath.Join(workdir, rep.BottleFile)
+	ok, err := Verify(bottlePath, rep.Sha256)
+	if err != nil || !ok {
+		return nil, "", err
+	}
+	return rep, bottlePath, nil
+}
+
+// Verify prüft die sha256 eines Bottles. Fehlt die Datei: false, nil.
+func Verify(bottlePath, sha string) (bool, error) {
+	if _, err := os.Stat(bottlePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	sum, err := hash.FileSHA256(bottlePath)
+	if err != nil {
+		return false, err
+	}
+	return sum == sha, nil
+}
@@ -18,6 +18,10 @@ type Config struct {
 	NexusPrefix string
 	NexusUpload bool
 
+	// nur mit --nexus-upload
+	UploadAll      bool
+	FormulaVersion string
+
 	BuildBottle bool
 	Upload      bool
 	KeepWork    bool
@@ -28,6 +32,36 @@ type Config struct {
 	TapGitBranch string
 
 	TapWorkdir string
+
+	OCIPush      bool
+	OCIRegistry  string
+	OCINamespace string
+
+	Sign    bool
+	SignKey string
+
+	SBOM       bool
+	Provenance bool
+
+	ForceBuild bool
+	Resume     bool
+
+	ReportFile  string
+	MetricsFile string
+
+	NotifyConfig string
+
+	JUnitFile string
+
+	LockTimeout string
+	LockStale   string
+
+	UploadJobs     int    // 0 = UPLOAD_JOBS
+	BandwidthLimit string // z.B. 10M, leer = UPLOAD_BANDWIDTH_LIMIT
+	SHA256File     bool
+
+	Reproducible bool // Bottle normalisieren, default REPRODUCIBLE
+	ReproCheck   bool // zweimal bauen und vergleichen
 }
 
 type multiString []string
@@ -44,7 +78,7 @@ func ParseFlags(args []string) (Config, error) {
 	fs.SetOutput(io.Discard)
 
 	var refs multiString
-	fs.Var(&refs, "ref", "reference to tag")
+	fs.Var(&refs, "ref", "reference to tag (repeatable: refs run one after another)")
 
 	tag := fs.String("tag", "", "tag")
 	workDir := fs.String("work-dir", "", "work directory")
@@ -60,6 +94,8 @@ func ParseFlags(args []string) (Config, error) {
 	keepWork := fs.Bool("keep-work", false, "keep work dir")
 
 	nexusUpload := fs.Bool("nexus-upload", false, "upload existing bottle/json from workdir to Nexus (if alredy build)")
+	uploadAll := fs.Bool("all", false, "with --nexus-upload: upload every matching bottle instead of exactly one")
+	formulaVersion := fs.String("formula-version", "", "with --nexus-upload: only bottles of this version")
 
 	updateFormula := fs.Bool("update-formula", false, "update Formula bottle block based on dist/*.bottle.json")
 
@@ -68,27 +104,89 @@ func ParseFlags(args []string) (Config, error) {
 
 	tapWorkdir := fs.String("tap-workdir", "", "path to local tap git repo (where Formula/ lives)")
 
+	ociPush := fs.Bool("oci-push", false, "push bottle as OCI artifact (manifest + index) to --oci-registry")
+	ociRegistry := fs.String("oci-registry", "", "OCI registry base url (e.g. https://registry.example.ch); brew install needs HOMEBREW_ARTIFACT_DOMAIN set to it")
+	ociNamespace := fs.String("oci-namespace", "", "OCI namespace (default: tap of --ref, e.g. owner/tap)")
+
+	sign := fs.Bool("sign", false, "write detached signatures (.sig) for bottle and json, upload them alongside")
+	signKey := fs.String("sign-key", "", "signing key (path or <provider>:<location>), default SIGN_KEY")
+
+	sbom := fs.Bool("sbom", false, "generate CycloneDX + SPDX SBOM from the built bottle (uploaded with --upload)")
+	prov := fs.Bool("provenance", false, "write in-toto/SLSA provenance for the built bottle (signed with --sign)")
+
+	forceBuild := fs.Bool("force-build", false, "build even if dist/ or Nexus already has a bottle with the same cache key")
+
+	resume := fs.Bool("resume", false, "skip steps recorded in the workdir checkpoint whose outputs are still valid")
+
+	reportFile := fs.String("report-file", "", "additionally write the report to this path")
+	lockTimeout := fs.String("lock-timeout", "", "wait this long for workdir/brew prefix locks (0 = fail at once, -1 = forever), default LOCK_TIMEOUT")
+	lockStale := fs.String("lock-stale", "", "treat locks from other hosts older than this as stale, default LOCK_STALE_AFTER")
+	junitFile := fs.String("junit", "", "write JUnit XML (one testcase per step, classname = ref, or bottle with --nexus-upload) to this path")
+	notifyConfig := fs.String("notify-config", "", "JSON file with chat/webhook notifiers (slack, teams, json), default NOTIFY_CONFIG")
+	uploadJobs := fs.Int("upload-jobs", 0, "concurrent Nexus uploads (bottle, json, sidecars, several bottles with --all), default UPLOAD_JOBS")
+	bandwidth := fs.String("bandwidth-limit", "", "cap for all uploads together in bytes/s (e.g. 512K, 10M, 0 = unlimited), default UPLOAD_BANDWIDTH_LIMIT")
+	sha256File := fs.Bool("sha256-file", false, "write <bottle>.sha256 (sha256sum format) and upload it with the bottle, default UPLOAD_SHA256_FILE")
+	reproducible := fs.Bool("reproducible", false, "repack the bottle reproducibly (sorted, mtime SOURCE_DATE_EPOCH, uid/gid 0), default REPRODUCIBLE")
+	reproCheck := fs.Bool("repro-check", false, "build twice with --reproducible and fail if the bottles differ")
+	metricsFile := fs.String("metrics-file", "", "write Prometheus metrics (textfile collector, .prom), default METRICS_FILE")
+
 	if err := fs.Parse(args); err != nil {
 		return Config{}, err
 	}
 
 	cfg := Config{
-		Refs:          []string(refs),
-		Tag:           *tag,
-		WorkDir:       *workDir,
-		DryRun:        *dryRun,
-		NexusBase:     *nBase,
-		NexusUser:     *nUser,
-		NexusPass:     *nPass,
-		NexusPrefix:   *nPrefix,
-		NexusUpload:   *nexusUpload,
-		BuildBottle:   *buildBottle,
-		Upload:        *upload,
-		KeepWork:      *keepWork,
-		UpdateFormula: *updateFormula,
-		TapGitURL:     *TapGitURL,
-		TapGitBranch:  *TapGitBranch,
-		TapWorkdir:    *tapWorkdir,
+		Refs:           []string(refs),
+		Tag:            *tag,
+		WorkDir:        *workDir,
+		DryRun:         *dryRun,
+		NexusBase:      *nBase,
+		NexusUser:      *nUser,
+		NexusPass:      *nPass,
+		NexusPrefix:    *nPrefix,
+		NexusUpload:    *nexusUpload,
+		UploadAll:      *uploadAll,
+		FormulaVersion: *formulaVersion,
+		BuildBottle:    *buildBottle,
+		Upload:         *upload,
+		KeepWork:       *keepWork,
+		UpdateFormula:  *updateFormula,
+		TapGitURL:      *TapGitURL,
+		TapGitBranch:   *TapGitBranch,
+		TapWorkdir:     *tapWorkdir,
+		OCIPush:        *ociPush,
+		OCIRegistry:    *ociRegistry,
+		OCINamespace:   *ociNamespace,
+		Sign:           *sign,
+		SignKey:        *signKey,
+		SBOM:           *sbom,
+		Provenance:     *prov,
+		ForceBuild:     *forceBuild,
+		Resume:         *resume,
+		ReportFile:     *reportFile,
+		MetricsFile:    *metricsFile,
+		NotifyConfig:   *notifyConfig,
+		JUnitFile:      *junitFile,
+		LockTimeout:    *lockTimeout,
+		LockStale:      *lockStale,
+		UploadJobs:     *uploadJobs,
+		BandwidthLimit: *bandwidth,
+		SHA256File:     *sha256File,
+		Reproducible:   *reproducible,
+		ReproCheck:     *reproCheck,
+	}
+
+	if cfg.UploadJobs < 0 {
+		return Config{}, fmt.Errorf("--upload-jobs must be >= 0 (0 = UPLOAD_JOBS)")
+	}
+
+	// repro-check: frisch bauen (kein Cache, kein Resume), normalisiert
+	if cfg.ReproCheck {
+		if cfg.Resume {
+			return Config{}, fmt.Errorf("--repro-check cannot be combined with --resume")
+		}
+		cfg.BuildBottle = true
+		cfg.ForceBuild = true
+		cfg.Reproducible = true
 	}
 
 	// Upload triggert auch --build-bottle
@@ -96,6 +194,11 @@ func ParseFlags(args []string) (Config, error) {
 		cfg.BuildBottle = true
 	}
 
+	// OCI push braucht ebenfalls ein gebautes Bottle
+	if cfg.OCIPush && !cfg.BuildBottle {
+		cfg.BuildBottle = true
+	}
+
 	// keep work mach ohne build keinen Sinn (bei Upload habe ich build auf true gesetzt!)
 	if cfg.KeepWork && !cfg.BuildBottle {
 		cfg.KeepWork = false
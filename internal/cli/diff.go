@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+type DiffConfig struct {
+	Old       string
+	New       string
+	Format    string
+	Out       string
+	JSON      string  // zusätzlich JSON in diese Datei (Text bleibt auf stdout/--out)
+	Threshold float64 // relativ, 0.25 = 25%
+}
+
+// ParseDiffFlags: gov-bottle diff [--format text|json] [--out <file>] [--json <file>] [--threshold <percent>] <old> <new>
+func ParseDiffFlags(args []string) (DiffConfig, error) {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	format := fs.String("format", "text", "output format: text or json")
+	out := fs.String("out", "", "write output to file instead of stdout")
+	jsonOut := fs.String("json", "", "also write the JSON result to this file")
+	threshold := fs.Float64("threshold", 25, "size change in percent from which a file is highlighted")
+
+	if err := fs.Parse(args); err != nil {
+		return DiffConfig{}, err
+	}
+	switch *format {
+	case "text", "json":
+	default:
+		return DiffConfig{}, fmt.Errorf("diff: unknown format %q (text, json)", *format)
+	}
+	if *jsonOut != "" && *jsonOut == *out {
+		return DiffConfig{}, fmt.Errorf("diff: --json and --out must be different files")
+	}
+	if *threshold < 0 {
+		return DiffConfig{}, fmt.Errorf("diff: --threshold must be >= 0")
+	}
+	if fs.NArg() != 2 {
+		return DiffConfig{}, fmt.Errorf("diff: expected <old> <new> (bottle path or URL)")
+	}
+
+	return DiffConfig{
+		Old:       fs.Arg(0),
+		New:       fs.Arg(1),
+		Format:    *format,
+		Out:       *out,
+		JSON:      *jsonOut,
+		Threshold: *threshold / 100,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+type GCConfig struct {
+	WorkDir    string
+	Keep       int
+	WorkMaxAge time.Duration
+	Orphans    bool
+	DryRun     bool
+	JSON       bool
+}
+
+// ParseGCFlags: gov-bottle gc [--work-dir <dir>] [--keep N] [--work-max-age 24h] [--orphans] [--dry-run] [--json]
+func ParseGCFlags(args []string) (GCConfig, error) {
+	fs := flag.NewFlagSet("gc", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	workDir := fs.String("work-dir", "", "work directory to clean up")
+	keep := fs.Int("keep", 3, "keep the newest N versions per formula and tag (0 = keep all)")
+	workMaxAge := fs.Duration("work-max-age", 24*time.Hour, "remove work-*/repro-* dirs and .part/.tmp/.norm files older than this (0 = keep)")
+	orphans := fs.Bool("orphans", false, "remove reports without bottle and bottles without report (incl. sidecars)")
+	dryRun := fs.Bool("dry-run", false, "only show what would be removed")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return GCConfig{}, err
+	}
+	if *keep < 0 {
+		return GCConfig{}, fmt.Errorf("gc: --keep must be >= 0")
+	}
+
+	return GCConfig{
+		WorkDir:    *workDir,
+		Keep:       *keep,
+		WorkMaxAge: *workMaxAge,
+		Orphans:    *orphans,
+		DryRun:     *dryRun,
+		JSON:       *asJSON,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+type ScanConfig struct {
+	TapWorkdir string
+	WorkDir    string
+	Tags       []string
+	Nexus      bool
+	NexusBase  string
+	Format     string
+	Out        string
+}
+
+// ParseScanFlags: gov-bottle scan [--tap-workdir <dir>] [--work-dir <dir>] [--nexus] [--format table|json|markdown]
+func ParseScanFlags(args []string) (ScanConfig, error) {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var tags multiString
+	fs.Var(&tags, "tag", "tag to include even without bottles (repeatable)")
+
+	tapWorkdir := fs.String("tap-workdir", "", "path to local tap git repo (where Formula/ lives)")
+	workDir := fs.String("work-dir", "", "work directory with *.bottle.json reports")
+	nx := fs.Bool("nexus", false, "also cross-check the Nexus listing")
+	nBase := fs.String("nexus-base", "", "nexus base")
+	format := fs.String("format", "table", "output format: table, json or markdown")
+	out := fs.String("out", "", "write output to file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return ScanConfig{}, err
+	}
+
+	switch *format {
+	case "table", "json", "markdown":
+	default:
+		return ScanConfig{}, fmt.Errorf("scan: unknown format %q (table, json, markdown)", *format)
+	}
+
+	return ScanConfig{
+		TapWorkdir: *tapWorkdir,
+		WorkDir:    *workDir,
+		Tags:       []string(tags),
+		Nexus:      *nx,
+		NexusBase:  *nBase,
+		Format:     *format,
+		Out:        *out,
+	}, nil
+}
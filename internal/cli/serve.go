@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"flag"
+	"io"
+)
+
+type ServeConfig struct {
+	Listen   string
+	StateDir string
+	Token    string
+
+	TapWorkdir string
+}
+
+// ParseServeFlags: gov-bottle serve [--listen 127.0.0.1:8080] [--state-dir <dir>] [--token <t>]
+func ParseServeFlags(args []string) (ServeConfig, error) {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	listen := fs.String("listen", "", "listen address, default SERVE_LISTEN or 127.0.0.1:8080 (other than loopback only with a token)")
+	stateDir := fs.String("state-dir", "", "directory for the persistent job queue, default SERVE_STATE_DIR or <workdir>/.serve")
+	token := fs.String("token", "", "bearer token for the API, default SERVE_TOKEN")
+	tapWorkdir := fs.String("tap-workdir", "", "local tap clone used by the push webhook, default TAP_WORKDIR")
+
+	if err := fs.Parse(args); err != nil {
+		return ServeConfig{}, err
+	}
+	return ServeConfig{Listen: *listen, StateDir: *stateDir, Token: *token, TapWorkdir: *tapWorkdir}, nil
+}
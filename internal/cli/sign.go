@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+type SignConfig struct {
+	Key   string
+	Files []string
+}
+
+// ParseSignFlags: gov-bottle sign [--key <spec>] <file>...
+func ParseSignFlags(args []string) (SignConfig, error) {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	key := fs.String("key", "", "signing key (path or <provider>:<location>), default SIGN_KEY")
+
+	if err := fs.Parse(args); err != nil {
+		return SignConfig{}, err
+	}
+	if fs.NArg() == 0 {
+		return SignConfig{}, fmt.Errorf("sign: at least one file must be specified")
+	}
+	return SignConfig{Key: *key, Files: fs.Args()}, nil
+}
+
+type VerifySignatureConfig struct {
+	TrustedKeys string
+	Files       []string
+}
+
+// ParseVerifySignatureFlags: gov-bottle verify-signature [--trusted-keys <file>] <file>...
+func ParseVerifySignatureFlags(args []string) (VerifySignatureConfig, error) {
+	fs := flag.NewFlagSet("verify-signature", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	trusted := fs.String("trusted-keys", "", "trusted public keys file, default TRUSTED_KEYS")
+
+	if err := fs.Parse(args); err != nil {
+		return VerifySignatureConfig{}, err
+	}
+	if fs.NArg() == 0 {
+		return VerifySignatureConfig{}, fmt.Errorf("verify-signature: at least one file must be specified")
+	}
+	return VerifySignatureConfig{TrustedKeys: *trusted, Files: fs.Args()}, nil
+}
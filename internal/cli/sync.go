@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+type SyncConfig struct {
+	WorkDir   string
+	NexusBase string
+	Tag       string
+	Pull      bool
+	Jobs      int
+	Bandwidth string
+	DryRun    bool
+	JSON      bool
+}
+
+// ParseSyncFlags: gov-bottle sync [--work-dir <dir>] [--nexus-base <url>] [--tag <tag>] [--pull] [--jobs N] [--bandwidth-limit R] [--dry-run] [--json]
+func ParseSyncFlags(args []string) (SyncConfig, error) {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	workDir := fs.String("work-dir", "", "local work directory (dist/)")
+	nBase := fs.String("nexus-base", "", "nexus base")
+	tag := fs.String("tag", "", "only bottles/reports of this tag")
+	pull := fs.Bool("pull", false, "download artifacts that only exist in Nexus")
+	jobs := fs.Int("jobs", 4, "concurrent transfers")
+	bandwidth := fs.String("bandwidth-limit", "", "cap for all uploads together in bytes/s (e.g. 10M), default UPLOAD_BANDWIDTH_LIMIT")
+	dryRun := fs.Bool("dry-run", false, "only print the plan")
+	asJSON := fs.Bool("json", false, "print plan and summary as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return SyncConfig{}, err
+	}
+	if *jobs < 1 {
+		return SyncConfig{}, fmt.Errorf("sync: --jobs must be >= 1")
+	}
+
+	return SyncConfig{
+		WorkDir:   *workDir,
+		NexusBase: *nBase,
+		Tag:       *tag,
+		Pull:      *pull,
+		Jobs:      *jobs,
+		Bandwidth: *bandwidth,
+		DryRun:    *dryRun,
+		JSON:      *asJSON,
+	}, nil
+}
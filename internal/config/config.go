@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -19,6 +20,52 @@ type Config struct {
 	BrewBin        string
 	HomebrewPrefix string
 	TapWorkdir     string
+
+	OCIRegistry  string
+	OCINamespace string
+	OCIUser      string
+	OCIPass      string
+
+	SignKey     string
+	TrustedKeys string
+
+	ServeListen   string
+	ServeStateDir string
+	ServeToken    string
+	ServePrefixes []string // erlaubte brew_prefix Werte neben HOMEBREW_PREFIX
+
+	WebhookSecret string
+	WebhookSteps  []string
+	TapName       string
+	TapGitBranch  string
+
+	MetricsFile  string
+	NotifyConfig string
+
+	LockTimeout    string
+	LockStaleAfter string
+
+	UploadJobs  string
+	UploadLimit string
+	SHA256File  bool // <bottle>.sha256 mit hochladen
+
+	// Grenzen der Bottle-Validierung (.tar.gz bzw. entpackt)
+	MinSize     string
+	MaxSize     string
+	MaxUnpacked string
+
+	// reproduzierbare Bottles (sortiert, feste mtime, gzip ohne Header-Zeit)
+	Reproducible bool
+	SourceEpoch  string // SOURCE_DATE_EPOCH, leer = 0
+
+	// ausgehendes HTTP (Nexus, OCI, Notifications)
+	TLSCABundle   string
+	TLSClientCert string
+	TLSClientKey  string
+	TLSMinVersion string
+	TLSInsecure   bool
+	ProxyURL      string
+	NoProxy       []string
 }
 
 func LoadEnv() {
@@ -36,6 +83,39 @@ func FromEnv() Config {
 		BrewBin:        getenvDefault("BREW_BIN", "brew"),
 		HomebrewPrefix: getenvDefault("HOMEBREW_PREFIX", "/opt/homebrew"),
 		TapWorkdir:     getenvDefault("TAP_WORKDIR", "./tap"),
+		OCIRegistry:    os.Getenv("OCI_REGISTRY"),
+		OCINamespace:   os.Getenv("OCI_NAMESPACE"),
+		OCIUser:        os.Getenv("OCI_USER"),
+		OCIPass:        os.Getenv("OCI_PASS"),
+		SignKey:        os.Getenv("SIGN_KEY"),
+		TrustedKeys:    os.Getenv("TRUSTED_KEYS"),
+		ServeListen:    getenvDefault("SERVE_LISTEN", "127.0.0.1:8080"),
+		ServeStateDir:  os.Getenv("SERVE_STATE_DIR"),
+		ServeToken:     os.Getenv("SERVE_TOKEN"),
+		ServePrefixes:  splitList(os.Getenv("SERVE_BREW_PREFIXES")),
+		WebhookSecret:  os.Getenv("WEBHOOK_SECRET"),
+		WebhookSteps:   splitList(getenvDefault("WEBHOOK_STEPS", "build,upload")),
+		TapName:        os.Getenv("TAP_NAME"),
+		TapGitBranch:   os.Getenv("TAP_GIT_BRANCH"),
+		MetricsFile:    os.Getenv("METRICS_FILE"),
+		NotifyConfig:   os.Getenv("NOTIFY_CONFIG"),
+		LockTimeout:    getenvDefault("LOCK_TIMEOUT", "0"),
+		LockStaleAfter: getenvDefault("LOCK_STALE_AFTER", "24h"),
+		UploadJobs:     getenvDefault("UPLOAD_JOBS", "4"),
+		UploadLimit:    os.Getenv("UPLOAD_BANDWIDTH_LIMIT"),
+		SHA256File:     getenvBool("UPLOAD_SHA256_FILE"),
+		MinSize:        getenvDefault("BOTTLE_MIN_SIZE", ""),
+		MaxSize:        getenvDefault("BOTTLE_MAX_SIZE", "2G"),
+		MaxUnpacked:    getenvDefault("BOTTLE_MAX_UNPACKED", "8G"),
+		Reproducible:   getenvBool("REPRODUCIBLE"),
+		SourceEpoch:    os.Getenv("SOURCE_DATE_EPOCH"),
+		TLSCABundle:    os.Getenv("TLS_CA_BUNDLE"),
+		TLSClientCert:  os.Getenv("TLS_CLIENT_CERT"),
+		TLSClientKey:   os.Getenv("TLS_CLIENT_KEY"),
+		TLSMinVersion:  getenvDefault("TLS_MIN_VERSION", "1.2"),
+		TLSInsecure:    getenvBool("TLS_INSECURE"),
+		ProxyURL:       os.Getenv("PROXY_URL"),
+		NoProxy:        splitList(os.Getenv("PROXY_NO_PROXY")),
 	}
 }
 
@@ -52,6 +132,26 @@ func (c Config) ValidateForUpload() error {
 	return nil
 }
 
+// splitList: "a, b,c" -> [a b c]
+func splitList(v string) []string {
+	var out []string
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// getenvBool: 1/true/yes/on (Groß/klein egal)
+func getenvBool(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
 func getenvDefault(key, def string) string {
 	v := os.Getenv(key)
 	if v == "" {
@@ -0,0 +1,207 @@
+package diff
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/bottle"
+	"gov-brew-bottle-creation/internal/linkage"
+)
+
+// Entry: eine Datei im Bottle, Pfad ohne <formula>/<version>/
+type Entry struct {
+	Type   string `json:"type"` // file, symlink, hardlink, other
+	Size   int64  `json:"size"`
+	Mode   string `json:"mode"` // oktal, inkl. setuid/setgid/sticky
+	Link   string `json:"link,omitempty"`
+	Sha256 string `json:"sha256,omitempty"`
+	Binary bool   `json:"binary,omitempty"` // ELF/Mach-O oder ausführbar in bin/, sbin/
+}
+
+// Side: eine der beiden Seiten des Vergleichs
+type Side struct {
+	Source   string `json:"source"`
+	Formula  string `json:"formula"`
+	Version  string `json:"version"`
+	Files    int    `json:"files"`
+	Unpacked int64  `json:"unpacked"` // Summe der Dateigrößen
+	Archive  int64  `json:"archive"`  // Größe des .tar.gz
+
+	entries map[string]Entry
+}
+
+const (
+	Added    = "added"
+	Removed  = "removed"
+	Modified = "modified"
+)
+
+type Change struct {
+	Path      string   `json:"path"`
+	Kind      string   `json:"kind"`
+	Fields    []string `json:"fields,omitempty"` // bei modified: type, size, mode, link, content
+	Old       *Entry   `json:"old,omitempty"`
+	New       *Entry   `json:"new,omitempty"`
+	SizeDelta int64    `json:"size_delta"`
+	BigChange bool     `json:"big_change,omitempty"`
+}
+
+type Result struct {
+	Old       Side     `json:"old"`
+	New       Side     `json:"new"`
+	Added     int      `json:"added"`
+	Removed   int      `json:"removed"`
+	Modified  int      `json:"modified"`
+	Unchanged int      `json:"unchanged"`
+	Changes   []Change `json:"changes"`
+
+	RemovedBinaries []string `json:"removed_binaries,omitempty"`
+	BigChanges      []string `json:"big_changes,omitempty"`
+	Threshold       float64  `json:"threshold"` // relative Größenänderung für BigChange, z.B. 0.25
+}
+
+// minBigChange: kleinere Änderungen gelten nie als groß (README 1 KiB -> 2 KiB)
+const minBigChange = 64 << 10
+
+// Read liest ein Bottle; source landet nur im Ergebnis (Pfad oder URL).
+func Read(bottlePath, source string) (Side, error) {
+	s := Side{Source: source, entries: map[string]Entry{}}
+	if fi, err := os.Stat(bottlePath); err == nil {
+		s.Archive = fi.Size()
+	}
+
+	err := bottle.Walk(bottlePath, func(hdr *tar.Header, r io.Reader) error {
+		name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if s.Formula == "" {
+			if parts := strings.SplitN(name, "/", 3); len(parts) >= 2 {
+				s.Formula, s.Version = parts[0], parts[1]
+			}
+		}
+		rel := bottle.StripPrefix(hdr.Name)
+		if rel == "" || hdr.Typeflag == tar.TypeDir {
+			return nil
+		}
+
+		e := Entry{Mode: fmt.Sprintf("%04o", hdr.Mode&07777)}
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			e.Type, e.Size = "file", hdr.Size
+			h := sha256.New()
+			magic := make([]byte, 4)
+			n, err := io.ReadFull(r, magic)
+			if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+				return fmt.Errorf("read %s: %w", rel, err)
+			}
+			h.Write(magic[:n])
+			if _, err := io.Copy(h, r); err != nil {
+				return fmt.Errorf("read %s: %w", rel, err)
+			}
+			e.Sha256 = hex.EncodeToString(h.Sum(nil))
+			dir, _, _ := strings.Cut(rel, "/")
+			e.Binary = linkage.Format(magic[:n]) != "" || ((dir == "bin" || dir == "sbin") && hdr.Mode&0o111 != 0)
+			s.Unpacked += hdr.Size
+		case tar.TypeSymlink:
+			e.Type, e.Link = "symlink", hdr.Linkname
+		case tar.TypeLink:
+			e.Type, e.Link = "hardlink", bottle.StripPrefix(hdr.Linkname)
+		default:
+			e.Type = "other"
+		}
+		s.entries[rel] = e
+		return nil
+	})
+	if err != nil {
+		return Side{}, err
+	}
+	s.Files = len(s.entries)
+	return s, nil
+}
+
+// Compare: threshold ist die relative Größenänderung, ab der eine Datei als groß geändert gilt.
+func Compare(from, to Side, threshold float64) Result {
+	res := Result{Old: from, New: to, Changes: []Change{}, Threshold: threshold}
+
+	paths := make([]string, 0, len(from.entries)+len(to.entries))
+	for p := range from.entries {
+		paths = append(paths, p)
+	}
+	for p := range to.entries {
+		if _, ok := from.entries[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		o, inOld := from.entries[p]
+		n, inNew := to.entries[p]
+		c := Change{Path: p}
+		switch {
+		case !inNew:
+			c.Kind, c.Old, c.SizeDelta = Removed, &o, -o.Size
+			res.Removed++
+			if o.Binary {
+				res.RemovedBinaries = append(res.RemovedBinaries, p)
+			}
+		case !inOld:
+			c.Kind, c.New, c.SizeDelta = Added, &n, n.Size
+			res.Added++
+		default:
+			c.Fields = fields(o, n)
+			if len(c.Fields) == 0 {
+				res.Unchanged++
+				continue
+			}
+			c.Kind, c.Old, c.New, c.SizeDelta = Modified, &o, &n, n.Size-o.Size
+			res.Modified++
+		}
+		c.BigChange = big(c, threshold)
+		if c.BigChange {
+			res.BigChanges = append(res.BigChanges, p)
+		}
+		res.Changes = append(res.Changes, c)
+	}
+	return res
+}
+
+func fields(o, n Entry) []string {
+	var f []string
+	if o.Type != n.Type {
+		f = append(f, "type")
+	}
+	if o.Size != n.Size {
+		f = append(f, "size")
+	}
+	if o.Mode != n.Mode {
+		f = append(f, "mode")
+	}
+	if o.Link != n.Link {
+		f = append(f, "link")
+	}
+	if o.Sha256 != n.Sha256 && o.Size == n.Size {
+		f = append(f, "content") // bei anderer Größe sowieso anders
+	}
+	return f
+}
+
+// big: |Änderung| >= minBigChange und >= threshold relativ zur alten Größe
+func big(c Change, threshold float64) bool {
+	d := c.SizeDelta
+	if d < 0 {
+		d = -d
+	}
+	if d < minBigChange {
+		return false
+	}
+	if c.Kind != Modified {
+		return true
+	}
+	return float64(d) >= threshold*float64(c.Old.Size)
+}
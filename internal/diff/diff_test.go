@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+type entry struct {
+	name string
+	size int
+	mode int64
+	fill byte // Inhalt: size mal fill
+}
+
+func writeBottle(t *testing.T, name string, entries []entry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for _, e := range entries {
+		mode := e.mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: mode, Size: int64(e.size)}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(bytes.Repeat([]byte{e.fill}, e.size)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestCompareBigChanges(t *testing.T) {
+	const kib = 1024
+	old := writeBottle(t, "old.bottle.tar.gz", []entry{
+		{name: "gov-srt/1.5.3/README.md", size: 1 * kib, fill: 'a'},
+		{name: "gov-srt/1.5.3/lib/libsrt.a", size: 256 * kib, fill: 'a'},
+		{name: "gov-srt/1.5.3/lib/libsrt.dylib", size: 1024 * kib, fill: 'a'},
+		{name: "gov-srt/1.5.3/bin/srt-tool", size: 100 * kib, mode: 0o755, fill: 'a'},
+		{name: "gov-srt/1.5.3/share/doc.txt", size: 10, fill: 'a'},
+		{name: "gov-srt/1.5.3/share/same.txt", size: 10, fill: 'a'},
+	})
+	cur := writeBottle(t, "new.bottle.tar.gz", []entry{
+		{name: "gov-srt/1.5.4/README.md", size: 2 * kib, fill: 'a'},           // +100 %, aber unter 64 KiB
+		{name: "gov-srt/1.5.4/lib/libsrt.a", size: 400 * kib, fill: 'a'},      // +56 %, 144 KiB
+		{name: "gov-srt/1.5.4/lib/libsrt.dylib", size: 1124 * kib, fill: 'a'}, // +100 KiB, aber nur +10 %
+		{name: "gov-srt/1.5.4/share/new.dat", size: 70 * kib, fill: 'b'},
+		{name: "gov-srt/1.5.4/share/doc.txt", size: 10, fill: 'b'},
+		{name: "gov-srt/1.5.4/share/same.txt", size: 10, fill: 'a'},
+	})
+
+	from, err := Read(old, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := Read(cur, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from.Version != "1.5.3" || to.Version != "1.5.4" || to.Files != 6 {
+		t.Fatalf("sides = %+v / %+v", from, to)
+	}
+
+	res := Compare(from, to, 0.25)
+	if res.Added != 1 || res.Removed != 1 || res.Modified != 4 || res.Unchanged != 1 {
+		t.Fatalf("added/removed/modified/unchanged = %d/%d/%d/%d", res.Added, res.Removed, res.Modified, res.Unchanged)
+	}
+	want := []string{"bin/srt-tool", "lib/libsrt.a", "share/new.dat"}
+	if !slices.Equal(res.BigChanges, want) {
+		t.Fatalf("big changes = %v, want %v", res.BigChanges, want)
+	}
+	if !slices.Equal(res.RemovedBinaries, []string{"bin/srt-tool"}) {
+		t.Fatalf("removed binaries = %v", res.RemovedBinaries)
+	}
+	for _, c := range res.Changes {
+		if c.Path == "share/doc.txt" && !slices.Equal(c.Fields, []string{"content"}) {
+			t.Errorf("doc.txt fields = %v, want [content]", c.Fields)
+		}
+	}
+
+	// kleinere Schwelle: auch +10 % gilt als gross
+	res = Compare(from, to, 0.05)
+	if !slices.Contains(res.BigChanges, "lib/libsrt.dylib") || slices.Contains(res.BigChanges, "README.md") {
+		t.Fatalf("big changes at 5%% = %v", res.BigChanges)
+	}
+}
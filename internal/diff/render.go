@@ -0,0 +1,115 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/fsutil"
+)
+
+func WriteJSON(w io.Writer, r Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText: Zusammenfassung, dann entfernte Binaries und große Änderungen, dann alle Änderungen
+func WriteText(w io.Writer, r Result) error {
+	var b strings.Builder
+	for _, s := range []struct {
+		label string
+		side  Side
+	}{{"old", r.Old}, {"new", r.New}} {
+		fmt.Fprintf(&b, "%s: %s %s (%s), %d files, %s unpacked, %s archive\n", s.label, s.side.Formula, s.side.Version,
+			s.side.Source, s.side.Files, fsutil.HumanSize(s.side.Unpacked), fsutil.HumanSize(s.side.Archive))
+	}
+	fmt.Fprintf(&b, "summary: %d added, %d removed, %d modified, %d unchanged; unpacked %s\n",
+		r.Added, r.Removed, r.Modified, r.Unchanged, delta(r.Old.Unpacked, r.New.Unpacked))
+
+	if len(r.RemovedBinaries) > 0 {
+		fmt.Fprintf(&b, "\n!! removed binaries (%d):\n", len(r.RemovedBinaries))
+		for _, p := range r.RemovedBinaries {
+			fmt.Fprintf(&b, "  %s\n", p)
+		}
+	}
+	if len(r.BigChanges) > 0 {
+		fmt.Fprintf(&b, "\n!! big size changes (>= %.0f%%):\n", r.Threshold*100)
+		for _, c := range r.Changes {
+			if c.BigChange {
+				fmt.Fprintf(&b, "  %s  %s\n", c.Path, sizes(c))
+			}
+		}
+	}
+
+	if len(r.Changes) > 0 {
+		b.WriteString("\nchanges:\n")
+	}
+	for _, c := range r.Changes {
+		switch c.Kind {
+		case Added:
+			fmt.Fprintf(&b, "  + %s  %s\n", c.Path, describe(*c.New))
+		case Removed:
+			fmt.Fprintf(&b, "  - %s  %s\n", c.Path, describe(*c.Old))
+		case Modified:
+			var parts []string
+			for _, f := range c.Fields {
+				switch f {
+				case "type":
+					parts = append(parts, fmt.Sprintf("type %s -> %s", c.Old.Type, c.New.Type))
+				case "size":
+					parts = append(parts, "size "+sizes(c))
+				case "mode":
+					parts = append(parts, fmt.Sprintf("mode %s -> %s", c.Old.Mode, c.New.Mode))
+				case "link":
+					parts = append(parts, fmt.Sprintf("link %s -> %s", c.Old.Link, c.New.Link))
+				case "content":
+					parts = append(parts, "content")
+				}
+			}
+			fmt.Fprintf(&b, "  ~ %s  %s\n", c.Path, strings.Join(parts, ", "))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func describe(e Entry) string {
+	switch e.Type {
+	case "symlink", "hardlink":
+		return e.Type + " -> " + e.Link
+	case "file":
+		s := fsutil.HumanSize(e.Size) + " " + e.Mode
+		if e.Binary {
+			s += " binary"
+		}
+		return s
+	}
+	return e.Type
+}
+
+func sizes(c Change) string {
+	var from, to int64
+	if c.Old != nil {
+		from = c.Old.Size
+	}
+	if c.New != nil {
+		to = c.New.Size
+	}
+	return fmt.Sprintf("%s -> %s (%s)", fsutil.HumanSize(from), fsutil.HumanSize(to), delta(from, to))
+}
+
+// delta: "+1.2 MiB (+12%)"
+func delta(from, to int64) string {
+	d := to - from
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	s := sign + fsutil.HumanSize(d)
+	if from > 0 {
+		s += fmt.Sprintf(", %s%.0f%%", sign, float64(d)/float64(from)*100)
+	}
+	return s
+}
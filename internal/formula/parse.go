@@ -0,0 +1,112 @@
+package formula
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Info: was wir aus einer Formula .rb lesen (ohne Ruby auszuführen).
+type Info struct {
+	Name     string
+	Path     string
+	Version  string
+	Revision int
+	RootURL  string
+	Shas     map[string]string // tag -> sha256 aus dem (nicht auskommentierten) bottle-Block
+}
+
+var (
+	reVersion  = regexp.MustCompile(`^\s*version\s+"([^"]+)"`)
+	reURL      = regexp.MustCompile(`^\s*url\s+"([^"]+)"`)
+	reRevision = regexp.MustCompile(`^\s*revision\s+(\d+)`)
+	reRootURL  = regexp.MustCompile(`^\s*root_url\s+"([^"]+)"`)
+	// sha256 cellar: :any, arm64_tahoe: "..."  |  sha256 arm64_tahoe: "..."
+	reBottleSha = regexp.MustCompile(`^\s*sha256\s+(?:cellar:\s*(?::\w+|"[^"]*"),\s*)?(\w+):\s*"([0-9a-f]{64})"`)
+	reURLVer    = regexp.MustCompile(`\d+(?:\.\d+)+[a-z]?`)
+	reHeadStart = regexp.MustCompile(`^\s*head\s+do\s*$`)
+)
+
+// ParseFile liest Version, Revision und bottle-Block einer Formula.
+func ParseFile(p string) (Info, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return Info{}, fmt.Errorf("read formula: %w", err)
+	}
+	lines, err := readLines(b)
+	if err != nil {
+		return Info{}, fmt.Errorf("read lines: %w", err)
+	}
+
+	info := Info{
+		Name: strings.TrimSuffix(filepath.Base(p), ".rb"),
+		Path: p,
+		Shas: map[string]string{},
+	}
+
+	var firstURL string
+	inBottle, inHead := false, false
+	for _, ln := range lines {
+		switch {
+		case reHeadStart.MatchString(ln):
+			inHead = true
+			continue
+		case inHead:
+			if strings.TrimSpace(ln) == "end" {
+				inHead = false
+			}
+			continue
+		case inBottle:
+			if reBottleEnd.MatchString(ln) {
+				inBottle = false
+				continue
+			}
+			if m := reRootURL.FindStringSubmatch(ln); m != nil {
+				info.RootURL = m[1]
+			}
+			if m := reBottleSha.FindStringSubmatch(ln); m != nil {
+				info.Shas[m[1]] = m[2]
+			}
+			continue
+		case reBottleStart.MatchString(ln):
+			// auskommentierter Block zählt nicht
+			inBottle = !strings.HasPrefix(strings.TrimSpace(ln), "#")
+			continue
+		}
+
+		if m := reVersion.FindStringSubmatch(ln); m != nil && info.Version == "" {
+			info.Version = m[1]
+		}
+		if m := reURL.FindStringSubmatch(ln); m != nil && firstURL == "" {
+			firstURL = m[1]
+		}
+		if m := reRevision.FindStringSubmatch(ln); m != nil {
+			info.Revision, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	if info.Version == "" && firstURL != "" {
+		info.Version = versionFromURL(firstURL)
+	}
+	if info.Version == "" {
+		return info, fmt.Errorf("cannot determine version of %s", p)
+	}
+	return info, nil
+}
+
+// versionFromURL: letzte Versionsnummer im Dateinamen (srt-1.5.4.tar.gz, v1.5.4.tar.gz)
+func versionFromURL(u string) string {
+	base := path.Base(u)
+	for _, ext := range []string{".tar.gz", ".tar.xz", ".tar.bz2", ".tgz", ".zip", ".tar"} {
+		base = strings.TrimSuffix(base, ext)
+	}
+	m := reURLVer.FindAllString(base, -1)
+	if len(m) == 0 {
+		return ""
+	}
+	return m[len(m)-1]
+}
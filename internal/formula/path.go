@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // FormulaPathInRepo returns path like:
 // <tapWorkdir>/Formula/s/gov-srt.rb  (folder based on name without "gov-")
+// Falls back to the flat layout <tapWorkdir>/Formula/gov-srt.rb.
 func FormulaPathInRepo(tapWorkdir, formulaName string) (string, error) {
 	if tapWorkdir == "" {
 		return "", fmt.Errorf("tap workdir is empty")
@@ -25,8 +27,40 @@ func FormulaPathInRepo(tapWorkdir, formulaName string) (string, error) {
 	first := strings.ToLower(string([]rune(base)[0]))
 	p := filepath.Join(tapWorkdir, "Formula", first, filename)
 
-	if _, err := os.Stat(p); err != nil {
-		return "", fmt.Errorf("formula file not found: %s (%v)", p, err)
+	st, err := os.Stat(p)
+	if err == nil && !st.IsDir() {
+		return p, nil
 	}
-	return p, nil
+
+	flat := filepath.Join(tapWorkdir, "Formula", filename)
+	if st, ferr := os.Stat(flat); ferr == nil && !st.IsDir() {
+		return flat, nil
+	}
+	return "", fmt.Errorf("formula file not found: %s (%v)", p, err)
+}
+
+// ListFormulae liefert alle .rb unter <tapWorkdir>/Formula (sharded Formula/s/*.rb und flach Formula/*.rb).
+func ListFormulae(tapWorkdir string) ([]string, error) {
+	if tapWorkdir == "" {
+		return nil, fmt.Errorf("tap workdir is empty")
+	}
+	dir := filepath.Join(tapWorkdir, "Formula")
+
+	flat, err := filepath.Glob(filepath.Join(dir, "*.rb"))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+	sharded, err := filepath.Glob(filepath.Join(dir, "*", "*.rb"))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+
+	out := append(flat, sharded...)
+	if len(out) == 0 {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("formula dir: %w", err)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
 }
@@ -2,14 +2,23 @@ package formula
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
+// refPart: GitHub Owner/Repo bzw. Formula-Name (z.B. openssl@3, c++utils); nie mit "-" am Anfang
+var refPart = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@+-]*$`)
+
 // ref: owner/tap/formula
 func ParseRef(ref string) (tap string, formula string, err error) {
 	parts := strings.Split(ref, "/")
 	if len(parts) != 3 {
 		return "", "", fmt.Errorf("invalid ref %q, expected owner/tap/formula", ref)
 	}
+	for _, p := range parts {
+		if !refPart.MatchString(p) {
+			return "", "", fmt.Errorf("invalid ref %q, expected owner/tap/formula", ref)
+		}
+	}
 	return parts[0] + "/" + parts[1], parts[2], nil
 }
@@ -0,0 +1,114 @@
+package fsutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/hash"
+	"gov-brew-bottle-creation/internal/naming"
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// Bottle: Bottle im Workdir mit zugehörigem Report
+type Bottle struct {
+	naming.Parsed
+	Path     string
+	JSONPath string
+	Report   report.BottleReport
+}
+
+// Filter für FindBottles; leere Felder filtern nicht.
+type Filter struct {
+	Formulae []string
+	Version  string
+	Tag      string
+}
+
+func (f Filter) match(p naming.Parsed) bool {
+	if f.Version != "" && p.Version != f.Version {
+		return false
+	}
+	if f.Tag != "" && p.Tag != f.Tag {
+		return false
+	}
+	if len(f.Formulae) == 0 {
+		return true
+	}
+	for _, n := range f.Formulae {
+		if n == p.Formula {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) String() string {
+	var parts []string
+	if len(f.Formulae) > 0 {
+		parts = append(parts, "formula="+strings.Join(f.Formulae, ","))
+	}
+	if f.Version != "" {
+		parts = append(parts, "version="+f.Version)
+	}
+	if f.Tag != "" {
+		parts = append(parts, "tag="+f.Tag)
+	}
+	if len(parts) == 0 {
+		return "any"
+	}
+	return strings.Join(parts, " ")
+}
+
+// FindBottles sucht passende *.bottle.tar.gz über den Dateinamen und prüft sie gegen ihren
+// Report: gleiche formula/version/tag und, falls im Report vorhanden, gleiche sha256.
+// Ein passendes Bottle ohne (stimmigen) Report ist ein Fehler.
+func FindBottles(dir string, f Filter) ([]Bottle, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.bottle.tar.gz"))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+	sort.Strings(matches)
+
+	var out []Bottle
+	for _, p := range matches {
+		parsed, ok := naming.Parse(filepath.Base(p))
+		if !ok {
+			continue
+		}
+		if !f.match(parsed) {
+			continue
+		}
+
+		b := Bottle{
+			Parsed:   parsed,
+			Path:     p,
+			JSONPath: filepath.Join(dir, naming.BottleJSON(parsed.Formula, parsed.Version, parsed.Tag)),
+		}
+		raw, err := os.ReadFile(b.JSONPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: report missing: %w", filepath.Base(p), err)
+		}
+		if err := json.Unmarshal(raw, &b.Report); err != nil {
+			return nil, fmt.Errorf("%s: parse report: %w", filepath.Base(b.JSONPath), err)
+		}
+		r := b.Report
+		if r.Formula != parsed.Formula || r.Version != parsed.Version || r.Tag != parsed.Tag {
+			return nil, fmt.Errorf("%s: report is for %s %s (%s)", filepath.Base(p), r.Formula, r.Version, r.Tag)
+		}
+		if r.Sha256 != "" {
+			sum, err := hash.FileSHA256(p)
+			if err != nil {
+				return nil, err
+			}
+			if sum != r.Sha256 {
+				return nil, fmt.Errorf("%s: sha256 %s does not match report (%s)", filepath.Base(p), sum, r.Sha256)
+			}
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package fsutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseSize: Bytes mit optionaler Einheit K, M, G (1024er, wie HumanSize),
+// z.B. "512K", "10M", "1.5GiB"; "" = 0.
+func ParseSize(s string) (int64, error) {
+	v := strings.TrimSpace(s)
+	if v == "" {
+		return 0, nil
+	}
+	mult := 1.0
+	upper := strings.ToUpper(v)
+	for _, u := range []struct {
+		suffix string
+		mult   float64
+	}{
+		{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+		{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(upper, u.suffix) {
+			v, mult = strings.TrimSpace(v[:len(v)-len(u.suffix)]), u.mult
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g. 512K, 10M, 2G)", s)
+	}
+	return int64(f * mult), nil
+}
+
+// HumanSize: 1024er Einheiten, z.B. "1.5 MiB"
+func HumanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
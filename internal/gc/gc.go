@@ -0,0 +1,301 @@
+package gc
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gov-brew-bottle-creation/internal/hash"
+	"gov-brew-bottle-creation/internal/lock"
+	"gov-brew-bottle-creation/internal/naming"
+	"gov-brew-bottle-creation/internal/sign"
+)
+
+const (
+	ReasonWork    = "work"    // work-* Verzeichnis / .part / .tmp Reste
+	ReasonVersion = "version" // ältere Version über --keep hinaus
+	ReasonOrphan  = "orphan"  // Report ohne Bottle bzw. Bottle ohne Report
+)
+
+type Options struct {
+	Workdir      string
+	KeepVersions int           // pro formula+tag; 0 = alle behalten
+	WorkMaxAge   time.Duration // work-*, .part, .tmp älter als das; 0 = behalten
+	Orphans      bool
+
+	// Schutz für laufende Runs (Holder des Workdir-Locks)
+	ProtectFormulas map[string]bool
+	ProtectSince    time.Time // work-* Verzeichnisse ab diesem Zeitpunkt nicht anfassen
+}
+
+type Item struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+}
+
+type Result struct {
+	Items []Item `json:"items"`
+	Bytes int64  `json:"bytes"`
+}
+
+// group: alle Dateien zu formula-version.tag
+type group struct {
+	formula, version, tag string
+	files                 []string
+	bottle, report        bool
+}
+
+// Suffixe nach <formula>-<version>.<tag>, längste zuerst
+var suffixes = []string{
+	".bottle.tar.gz" + sign.Suffix,
+	".bottle.tar.gz" + hash.Suffix,
+	".bottle.json" + sign.Suffix,
+	naming.ProvenanceSuffix + sign.Suffix,
+	".bottle.tar.gz",
+	".bottle.json",
+	naming.SBOMCycloneDXSuffix,
+	naming.SBOMSPDXSuffix,
+	naming.ProvenanceSuffix,
+	naming.StateSuffix,
+}
+
+// Plan ermittelt, was gelöscht würde; es wird nichts verändert.
+func Plan(opts Options) (Result, error) {
+	entries, err := os.ReadDir(opts.Workdir)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var res Result
+	add := func(path, reason, detail string) {
+		size, _ := diskSize(path)
+		res.Items = append(res.Items, Item{Path: path, Size: size, Reason: reason, Detail: detail})
+		res.Bytes += size
+	}
+
+	groups := map[string]*group{}
+	now := time.Now()
+	for _, e := range entries {
+		name := e.Name()
+		path := filepath.Join(opts.Workdir, name)
+		if name == lock.FileName {
+			continue
+		}
+
+		// Reste abgebrochener Runs
+		if opts.WorkMaxAge > 0 && isWorkLeftover(e) {
+			fi, err := e.Info()
+			if err != nil {
+				continue
+			}
+			if now.Sub(fi.ModTime()) <= opts.WorkMaxAge {
+				continue
+			}
+			if e.IsDir() && !opts.ProtectSince.IsZero() && !fi.ModTime().Before(opts.ProtectSince) {
+				continue
+			}
+			add(path, ReasonWork, "older than "+opts.WorkMaxAge.String())
+			continue
+		}
+		if e.IsDir() {
+			continue
+		}
+
+		g, kind := parse(name)
+		if g == nil {
+			continue
+		}
+		key := g.formula + "\x00" + g.version + "\x00" + g.tag
+		if groups[key] == nil {
+			groups[key] = g
+		}
+		g = groups[key]
+		g.files = append(g.files, path)
+		switch kind {
+		case ".bottle.tar.gz":
+			g.bottle = true
+		case ".bottle.json":
+			g.report = true
+		}
+	}
+
+	// pro formula+tag nach Version sortieren (neueste zuerst)
+	byFT := map[string][]*group{}
+	for _, g := range groups {
+		if opts.ProtectFormulas[g.formula] {
+			continue
+		}
+		byFT[g.formula+"\x00"+g.tag] = append(byFT[g.formula+"\x00"+g.tag], g)
+	}
+	keys := make([]string, 0, len(byFT))
+	for k := range byFT {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		gs := byFT[k]
+		sort.Slice(gs, func(i, j int) bool { return CompareVersions(gs[i].version, gs[j].version) > 0 })
+
+		kept := 0
+		for _, g := range gs {
+			switch {
+			case opts.Orphans && g.report && !g.bottle:
+				g.addAll(add, ReasonOrphan, "report without bottle")
+			case opts.Orphans && g.bottle && !g.report:
+				g.addAll(add, ReasonOrphan, "bottle without report")
+			case opts.Orphans && !g.bottle && !g.report:
+				g.addAll(add, ReasonOrphan, "no bottle and no report")
+			case !g.bottle:
+				// nur Report (z.B. dry-run) zählt nicht als Version
+			case opts.KeepVersions > 0 && kept >= opts.KeepVersions:
+				g.addAll(add, ReasonVersion, fmt.Sprintf("%s %s older than the newest %d", g.formula, g.version, opts.KeepVersions))
+			default:
+				kept++
+			}
+		}
+	}
+	return res, nil
+}
+
+// Apply löscht die Einträge; Fehler werden gesammelt, freigegebene Bytes gezählt.
+func Apply(res Result) (int64, []error) {
+	var freed int64
+	var errs []error
+	for _, it := range res.Items {
+		if err := os.RemoveAll(it.Path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		freed += it.Size
+	}
+	return freed, errs
+}
+
+func (g *group) addAll(add func(path, reason, detail string), reason, detail string) {
+	sort.Strings(g.files)
+	for _, f := range g.files {
+		add(f, reason, detail)
+	}
+}
+
+func parse(name string) (*group, string) {
+	for _, suf := range suffixes {
+		if !strings.HasSuffix(name, suf) {
+			continue
+		}
+		stem := strings.TrimSuffix(name, suf)
+		p, ok := naming.Parse(stem + ".bottle.json")
+		if !ok {
+			return nil, ""
+		}
+		return &group{formula: p.Formula, version: p.Version, tag: p.Tag}, suf
+	}
+	return nil, ""
+}
+
+func isWorkLeftover(e fs.DirEntry) bool {
+	name := e.Name()
+	if e.IsDir() {
+		return strings.HasPrefix(name, "work-") || strings.HasPrefix(name, "repro-")
+	}
+	// .norm/.spool-*: abgebrochene Normalisierung (--reproducible)
+	return strings.HasSuffix(name, ".part") || strings.HasSuffix(name, ".tmp") ||
+		strings.HasSuffix(name, ".norm") || strings.HasPrefix(name, ".spool-")
+}
+
+func diskSize(path string) (int64, error) {
+	var n int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil && !d.IsDir() {
+			n += fi.Size()
+		}
+		return nil
+	})
+	return n, err
+}
+
+// CompareVersions vergleicht Versionen stückweise (Zahlen numerisch, sonst lexikalisch):
+// 1.10.0 > 1.9.2, 3.6.0_1 > 3.6.0, 2025-09-09 > 2025-01-31.
+func CompareVersions(a, b string) int {
+	pa, pb := chunks(a), chunks(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if c := compareChunk(pa[i], pb[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(pa) < len(pb):
+		return -1
+	case len(pa) > len(pb):
+		return 1
+	}
+	return 0
+}
+
+func chunks(v string) []string {
+	var out []string
+	start := -1
+	digit := false
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		isDigit := c >= '0' && c <= '9'
+		isAlpha := !isDigit && c != '.' && c != '-' && c != '_'
+		if !isDigit && !isAlpha {
+			if start >= 0 {
+				out = append(out, v[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && isDigit != digit {
+			out = append(out, v[start:i])
+			start = -1
+		}
+		if start < 0 {
+			start, digit = i, isDigit
+		}
+	}
+	if start >= 0 {
+		out = append(out, v[start:])
+	}
+	return out
+}
+
+func compareChunk(a, b string) int {
+	an, bn := isNum(a), isNum(b)
+	switch {
+	case an && bn:
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	case an:
+		return 1 // 1.0.1 > 1.0.rc1
+	case bn:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+func isNum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
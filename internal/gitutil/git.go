@@ -0,0 +1,89 @@
+package gitutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ZeroSHA: "before" bei neu angelegten Branches
+const ZeroSHA = "0000000000000000000000000000000000000000"
+
+// Error: git ist fehlgeschlagen oder nicht startbar (bewusst kein brew.ExitError,
+// sonst meldet gov-bottle den brew Exit-Code und "brew not found").
+type Error struct {
+	Args     []string
+	ExitCode int    // -1: nicht gestartet oder durch Signal beendet
+	Stderr   string // stderr ohne abschliessende Leerzeichen
+	Err      error
+}
+
+func (e *Error) Error() string {
+	s := fmt.Sprintf("git %s failed: exit %d: %v", strings.Join(e.Args, " "), e.ExitCode, e.Err)
+	if e.Stderr != "" {
+		// letzte Zeile reicht für die Meldung, der Rest steht in Stderr
+		s += fmt.Sprintf(" (stderr: %s)", e.Stderr[strings.LastIndexByte(e.Stderr, '\n')+1:])
+	}
+	return s
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Git führt git -C dir args... aus und liefert stdout ohne abschliessende Leerzeichen.
+func Git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		code := -1
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			code = ee.ExitCode()
+		}
+		return "", &Error{Args: args, ExitCode: code, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// IsSHA: volle Commit-ID (40 hex für SHA-1, 64 für SHA-256 Repos)
+func IsSHA(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// ChangedFiles liefert hinzugefügte/geänderte/umbenannte Dateien zwischen from und to unter paths.
+// Ist from leer oder ZeroSHA, wird nur der Commit to betrachtet. from/to müssen volle SHAs sein
+// (kommen aus Webhook-Payloads), --end-of-options verhindert, dass sie als Optionen gelesen werden.
+func ChangedFiles(ctx context.Context, dir, from, to string, paths ...string) ([]string, error) {
+	if !IsSHA(to) || (from != "" && !IsSHA(from)) {
+		return nil, fmt.Errorf("changed files: invalid revision range %q..%q (expected full commit SHAs)", from, to)
+	}
+	args := []string{"diff", "--name-only", "--diff-filter=AMR", "--end-of-options"}
+	if from == "" || from == ZeroSHA {
+		args = []string{"diff-tree", "--no-commit-id", "--name-only", "-r", "--root", "--diff-filter=AMR", "--end-of-options", to}
+	} else {
+		args = append(args, from, to)
+	}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+
+	out, err := Git(ctx, dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
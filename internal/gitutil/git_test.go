@@ -0,0 +1,54 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"gov-brew-bottle-creation/internal/brew"
+)
+
+func TestIsSHA(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{strings.Repeat("a", 40), true},
+		{strings.Repeat("F", 64), true},
+		{ZeroSHA, true},
+		{strings.Repeat("a", 39), false},
+		{strings.Repeat("a", 41), false},
+		{strings.Repeat("g", 40), false},
+		{"--output=" + strings.Repeat("a", 31), false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsSHA(tt.in); got != tt.want {
+			t.Errorf("IsSHA(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGitError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	_, err := Git(context.Background(), t.TempDir(), "rev-parse", "HEAD")
+	var ge *Error
+	if !errors.As(err, &ge) {
+		t.Fatalf("Git error = %T %v, want *gitutil.Error", err, err)
+	}
+	var be *brew.ExitError
+	if errors.As(err, &be) {
+		t.Fatalf("git failure reported as brew error: %v", err)
+	}
+	if ge.ExitCode <= 0 || ge.Stderr == "" {
+		t.Fatalf("exit code %d, stderr %q", ge.ExitCode, ge.Stderr)
+	}
+	last := ge.Stderr[strings.LastIndexByte(ge.Stderr, '\n')+1:]
+	if n := strings.Count(err.Error(), last); n != 1 {
+		t.Fatalf("stderr appears %d times in %q", n, err.Error())
+	}
+}
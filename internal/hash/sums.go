@@ -0,0 +1,72 @@
+package hash
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	stdhash "hash"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Suffix: Prüfsummen-Datei neben dem Artefakt (<datei>.sha256, Format wie sha256sum)
+const Suffix = ".sha256"
+
+// Sums: Prüfsummen einer Datei (hex), in einem Durchlauf berechnet
+type Sums struct {
+	SHA256 string
+	SHA1   string
+	MD5    string
+}
+
+// Summer berechnet sha256, sha1 und md5 gleichzeitig; als io.Writer z.B. hinter einem TeeReader.
+type Summer struct {
+	w            io.Writer
+	h256, h1, h5 stdhash.Hash
+}
+
+func NewSummer() *Summer {
+	s := &Summer{h256: sha256.New(), h1: sha1.New(), h5: md5.New()}
+	s.w = io.MultiWriter(s.h256, s.h1, s.h5)
+	return s
+}
+
+func (s *Summer) Write(b []byte) (int, error) { return s.w.Write(b) }
+
+func (s *Summer) Sums() Sums {
+	return Sums{
+		SHA256: hex.EncodeToString(s.h256.Sum(nil)),
+		SHA1:   hex.EncodeToString(s.h1.Sum(nil)),
+		MD5:    hex.EncodeToString(s.h5.Sum(nil)),
+	}
+}
+
+// ReaderSums liest r einmal und berechnet sha256, sha1 und md5 gleichzeitig.
+func ReaderSums(r io.Reader) (Sums, int64, error) {
+	s := NewSummer()
+	n, err := io.Copy(s, r)
+	if err != nil {
+		return Sums{}, n, fmt.Errorf("hash: %w", err)
+	}
+	return s.Sums(), n, nil
+}
+
+// WriteSidecar schreibt <path>.sha256 ("<sha256>  <name>\n", prüfbar mit sha256sum -c).
+// Ist sum leer, wird sie berechnet.
+func WriteSidecar(path, sum string) (string, error) {
+	if sum == "" {
+		var err error
+		if sum, err = FileSHA256(path); err != nil {
+			return "", err
+		}
+	}
+	out := path + Suffix
+	line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(path))
+	if err := os.WriteFile(out, []byte(line), 0o644); err != nil {
+		return "", fmt.Errorf("write %s: %w", filepath.Base(out), err)
+	}
+	return out, nil
+}
@@ -0,0 +1,120 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Options für alle ausgehenden HTTP-Verbindungen (Nexus, OCI Registry, Notifications).
+type Options struct {
+	CABundle   string // PEM, zusätzlich zu den System-CAs
+	ClientCert string // PEM, mTLS; nur zusammen mit ClientKey
+	ClientKey  string
+	MinVersion string // "1.0" .. "1.3"; leer = 1.2
+
+	// Proxy: leer = HTTPS_PROXY/HTTP_PROXY/NO_PROXY aus der Umgebung, "none" = direkt
+	Proxy   string
+	NoProxy []string // nur zusammen mit Proxy
+
+	Insecure bool // keine Zertifikatsprüfung
+}
+
+// New baut einen Client aus den Optionen. Ohne Timeout, Uploads können lange dauern;
+// Abbruch läuft über den Context der Requests.
+func New(o Options) (*http.Client, error) {
+	tc, err := tlsConfig(o)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := proxyFunc(o.Proxy, o.NoProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tc
+	tr.Proxy = proxy
+	return &http.Client{Transport: tr}, nil
+}
+
+func tlsConfig(o Options) (*tls.Config, error) {
+	minVersion, err := ParseTLSVersion(o.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	tc := &tls.Config{MinVersion: minVersion, InsecureSkipVerify: o.Insecure}
+
+	if o.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(o.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("ca bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca bundle %s: no PEM certificates found", o.CABundle)
+		}
+		tc.RootCAs = pool
+	}
+
+	switch {
+	case o.ClientCert != "" && o.ClientKey != "":
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	case o.ClientCert != "" || o.ClientKey != "":
+		return nil, fmt.Errorf("client certificate and key must be set together")
+	}
+	return tc, nil
+}
+
+// ParseTLSVersion: "1.2" / "tls1.2" -> tls.VersionTLS12; leer = 1.2
+func ParseTLSVersion(v string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v)), "tls") {
+	case "":
+		return tls.VersionTLS12, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported tls min version %q (use 1.0, 1.1, 1.2 or 1.3)", v)
+}
+
+func proxyFunc(proxy string, noProxy []string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case "none", "direct":
+		return nil, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %q", proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy url %q: scheme must be http, https or socks5", proxy)
+	}
+	m := newMatcher(noProxy)
+	return func(req *http.Request) (*url.URL, error) {
+		if m.bypass(req.URL) {
+			return nil, nil
+		}
+		return u, nil
+	}, nil
+}
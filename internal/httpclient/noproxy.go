@@ -0,0 +1,72 @@
+package httpclient
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// matcher für No-Proxy Einträge (wie NO_PROXY): "*", host, ".domain"/"domain"
+// (inkl. Subdomains), IP, CIDR; optional mit ":port".
+type matcher struct {
+	all   bool
+	nets  []*net.IPNet
+	hosts []hostPort
+}
+
+type hostPort struct {
+	host string // ohne führenden Punkt
+	port string // leer = alle Ports
+}
+
+func newMatcher(entries []string) matcher {
+	var m matcher
+	for _, e := range entries {
+		e = strings.ToLower(strings.TrimSpace(e))
+		switch {
+		case e == "":
+			continue
+		case e == "*":
+			m.all = true
+			continue
+		}
+		if _, n, err := net.ParseCIDR(e); err == nil {
+			m.nets = append(m.nets, n)
+			continue
+		}
+		host, port := e, ""
+		if h, p, err := net.SplitHostPort(e); err == nil {
+			host, port = h, p
+		}
+		host = strings.TrimPrefix(strings.Trim(host, "[]"), ".")
+		m.hosts = append(m.hosts, hostPort{host: host, port: port})
+	}
+	return m
+}
+
+func (m matcher) bypass(u *url.URL) bool {
+	if m.all {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, n := range m.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+	}
+	for _, h := range m.hosts {
+		if h.port != "" && h.port != port {
+			continue
+		}
+		if host == h.host || strings.HasSuffix(host, "."+h.host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Recorder sammelt die Schritte eines Runs als JUnit-Testcases (classname = Ref bzw. Bottle).
+type Recorder struct {
+	Suite string
+	Class string
+
+	started time.Time
+	cases   []testCase
+
+	cur      string
+	curStart time.Time
+}
+
+func New(suite, class string) *Recorder {
+	return &Recorder{Suite: suite, Class: class, started: time.Now()}
+}
+
+// Group beginnt die Testcases des nächsten Refs; ein offener Schritt gilt damit als bestanden.
+func (r *Recorder) Group(class string) {
+	r.Pass()
+	r.Class = class
+}
+
+// Begin startet einen Schritt; ein noch offener Schritt gilt damit als bestanden.
+func (r *Recorder) Begin(step string) {
+	if r.cur != "" {
+		r.Pass()
+	}
+	r.cur, r.curStart = step, time.Now()
+}
+
+// Pass beendet den offenen Schritt erfolgreich.
+func (r *Recorder) Pass() {
+	if r.cur == "" {
+		return
+	}
+	r.add(testCase{Name: r.cur, Time: seconds(time.Since(r.curStart))})
+	r.cur = ""
+}
+
+// Fail beendet den offenen Schritt als Fehler (detail z.B. stderr-Tail von brew).
+func (r *Recorder) Fail(msg, detail string) {
+	name, d := r.cur, time.Since(r.curStart)
+	if name == "" {
+		name, d = "run", 0
+	}
+	r.add(testCase{Name: name, Time: seconds(d), Failure: &failure{Message: msg, Type: "error", Text: detail}})
+	r.cur = ""
+}
+
+// Record fügt einen bereits abgeschlossenen Schritt hinzu (z.B. parallele Uploads); msg != "" = Fehler.
+func (r *Recorder) Record(step string, d time.Duration, msg string) {
+	c := testCase{Name: step, Time: seconds(d)}
+	if msg != "" {
+		c.Failure = &failure{Message: msg, Type: "error"}
+	}
+	r.add(c)
+}
+
+// Skip markiert einen nicht ausgeführten Schritt (dry-run, Cache-Treffer, ...).
+func (r *Recorder) Skip(step, msg string) {
+	r.add(testCase{Name: step, Time: seconds(0), Skipped: &skipped{Message: msg}})
+}
+
+// Close schliesst die aktuelle Gruppe ab: ein offener Schritt bei rc != 0 ist der fehlgeschlagene.
+func (r *Recorder) Close(rc int, detail string) {
+	if rc == 0 {
+		r.Pass()
+		return
+	}
+	if r.cur == "" && r.failed(r.Class) {
+		return
+	}
+	r.Fail(fmt.Sprintf("exit code %d", rc), detail)
+}
+
+// Failed: ein Testcase irgendeiner Gruppe ist fehlgeschlagen.
+func (r *Recorder) Failed() bool {
+	for _, c := range r.cases {
+		if c.Failure != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recorder) failed(class string) bool {
+	for _, c := range r.cases {
+		if c.Failure != nil && c.Classname == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recorder) add(c testCase) {
+	c.Classname = r.Class
+	r.cases = append(r.cases, c)
+}
+
+// WriteFile schreibt <testsuites> mit genau einer <testsuite>.
+func (r *Recorder) WriteFile(path string) error {
+	host, _ := os.Hostname()
+	s := testSuite{
+		Name:      r.Suite,
+		Hostname:  host,
+		Timestamp: r.started.UTC().Format("2006-01-02T15:04:05"),
+		Time:      seconds(time.Since(r.started)),
+		Tests:     len(r.cases),
+		Cases:     r.cases,
+	}
+	for _, c := range r.cases {
+		switch {
+		case c.Failure != nil:
+			s.Failures++
+		case c.Skipped != nil:
+			s.Skipped++
+		}
+	}
+	doc := testSuites{
+		Name: r.Suite, Tests: s.Tests, Failures: s.Failures, Skipped: s.Skipped, Time: s.Time,
+		Suites: []testSuite{s},
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), append(b, '\n')...), 0o644)
+}
+
+// Tail: letzte n Zeilen (für brew stderr)
+func Tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+type testSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Suites   []testSuite `xml:"testsuite"`
+}
+
+type testSuite struct {
+	Name      string     `xml:"name,attr"`
+	Hostname  string     `xml:"hostname,attr,omitempty"`
+	Timestamp string     `xml:"timestamp,attr"`
+	Tests     int        `xml:"tests,attr"`
+	Failures  int        `xml:"failures,attr"`
+	Errors    int        `xml:"errors,attr"`
+	Skipped   int        `xml:"skipped,attr"`
+	Time      string     `xml:"time,attr"`
+	Cases     []testCase `xml:"testcase"`
+}
+
+type testCase struct {
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *failure `xml:"failure,omitempty"`
+	Skipped   *skipped `xml:"skipped,omitempty"`
+}
+
+type failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
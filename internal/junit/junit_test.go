@@ -0,0 +1,64 @@
+package junit
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecorderGroups(t *testing.T) {
+	r := New("gov-bottle", "")
+
+	// erster Ref scheitert ohne offenen Schritt, zweiter läuft durch
+	r.Group("t/t/a")
+	r.Begin("plan")
+	r.Pass()
+	r.Close(3, "")
+	r.Group("t/t/b")
+	r.Begin("plan")
+	r.Begin("build")
+	r.Close(0, "")
+	// parallele Uploads nachträglich
+	r.Group("b.bottle.tar.gz")
+	r.Record("upload", time.Second, "connection refused")
+
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	if err := r.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc testSuites
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	type row struct{ class, name string }
+	want := []row{
+		{"t/t/a", "plan"}, {"t/t/a", "run"},
+		{"t/t/b", "plan"}, {"t/t/b", "build"},
+		{"b.bottle.tar.gz", "upload"},
+	}
+	cases := doc.Suites[0].Cases
+	if len(cases) != len(want) {
+		t.Fatalf("%d testcases, want %d: %+v", len(cases), len(want), cases)
+	}
+	for i, c := range cases {
+		if c.Classname != want[i].class || c.Name != want[i].name {
+			t.Errorf("case %d = %s/%s, want %s/%s", i, c.Classname, c.Name, want[i].class, want[i].name)
+		}
+	}
+	if cases[1].Failure == nil || cases[1].Failure.Message != "exit code 3" {
+		t.Errorf("failed ref without open step: %+v", cases[1].Failure)
+	}
+	if cases[4].Failure == nil || cases[4].Time != "1.000" {
+		t.Errorf("recorded upload = %+v", cases[4])
+	}
+	if doc.Failures != 2 || !r.Failed() {
+		t.Errorf("failures = %d, Failed() = %v", doc.Failures, r.Failed())
+	}
+}
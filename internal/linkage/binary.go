@@ -0,0 +1,157 @@
+package linkage
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// errSkip: kein gelinktes Binary (Objektdatei, Java class mit gleicher Magic)
+var errSkip = errors.New("not a linked binary")
+
+// Mach-O Load Commands mit dylib_command bzw. rpath_command
+const (
+	lcLoadDylib       = 0xc
+	lcIDDylib         = 0xd
+	lcLazyLoadDylib   = 0x20
+	lcLoadWeakDylib   = 0x80000018
+	lcRpath           = 0x8000001c
+	lcReexportDylib   = 0x8000001f
+	lcLoadUpwardDylib = 0x80000023
+)
+
+// Format: "elf", "macho", "fat" oder "" anhand der ersten 4 Bytes
+func Format(magic []byte) string {
+	if len(magic) < 4 {
+		return ""
+	}
+	if string(magic) == elf.ELFMAG {
+		return "elf"
+	}
+	switch binary.BigEndian.Uint32(magic) {
+	case macho.Magic32, macho.Magic64:
+		return "macho"
+	case macho.MagicFat, macho.MagicFat + 1: // fat / fat64
+		return "fat"
+	}
+	switch binary.LittleEndian.Uint32(magic) {
+	case macho.Magic32, macho.Magic64:
+		return "macho"
+	}
+	return ""
+}
+
+func parseELF(b []byte) (report.LinkedFile, error) {
+	lf := report.LinkedFile{Format: "elf"}
+	f, err := elf.NewFile(bytes.NewReader(b))
+	if err != nil {
+		return lf, err
+	}
+	defer f.Close()
+	if f.Type == elf.ET_REL {
+		return lf, errSkip
+	}
+
+	if lf.Libraries, err = f.DynString(elf.DT_NEEDED); err != nil {
+		return lf, err
+	}
+	if names, _ := f.DynString(elf.DT_SONAME); len(names) > 0 {
+		lf.InstallName = names[0]
+	}
+	for _, tag := range []elf.DynTag{elf.DT_RPATH, elf.DT_RUNPATH} {
+		vals, _ := f.DynString(tag)
+		for _, v := range vals {
+			for _, p := range strings.Split(v, ":") {
+				lf.RPaths = appendUnique(lf.RPaths, p)
+			}
+		}
+	}
+	for _, p := range f.Progs {
+		if p.Type == elf.PT_INTERP {
+			data, err := io.ReadAll(p.Open())
+			if err != nil {
+				return lf, err
+			}
+			lf.Interpreter = strings.TrimRight(string(data), "\x00")
+		}
+	}
+	return lf, nil
+}
+
+// parseMachO: thin oder fat (Universal); bei fat die Vereinigung aller Architekturen
+func parseMachO(b []byte, fat bool) (report.LinkedFile, error) {
+	lf := report.LinkedFile{Format: "macho"}
+	if !fat {
+		f, err := macho.NewFile(bytes.NewReader(b))
+		if err != nil {
+			return lf, err
+		}
+		defer f.Close()
+		if f.Type == macho.TypeObj {
+			return lf, errSkip
+		}
+		machoLoads(f, &lf)
+		return lf, nil
+	}
+
+	ff, err := macho.NewFatFile(bytes.NewReader(b))
+	if err != nil {
+		return lf, errSkip // 0xcafebabe ist auch die Magic von Java class Files
+	}
+	defer ff.Close()
+	for _, a := range ff.Arches {
+		if a.Type == macho.TypeObj {
+			return lf, errSkip
+		}
+		machoLoads(a.File, &lf)
+	}
+	return lf, nil
+}
+
+// machoLoads liest die Load Commands roh, debug/macho parst weak/reexport/LC_ID_DYLIB nicht
+func machoLoads(f *macho.File, lf *report.LinkedFile) {
+	for _, l := range f.Loads {
+		raw := l.Raw()
+		if len(raw) < 12 {
+			continue
+		}
+		name := cstring(raw, f.ByteOrder.Uint32(raw[8:12]))
+		switch f.ByteOrder.Uint32(raw) {
+		case lcLoadDylib, lcLoadWeakDylib, lcReexportDylib, lcLazyLoadDylib, lcLoadUpwardDylib:
+			lf.Libraries = appendUnique(lf.Libraries, name)
+		case lcIDDylib:
+			lf.InstallName = name
+		case lcRpath:
+			lf.RPaths = appendUnique(lf.RPaths, name)
+		}
+	}
+}
+
+func cstring(raw []byte, off uint32) string {
+	if int(off) >= len(raw) {
+		return ""
+	}
+	s := raw[off:]
+	if i := bytes.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return string(s)
+}
+
+func appendUnique(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+	for _, x := range list {
+		if x == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,281 @@
+package linkage
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/bottle"
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// Regeln
+const (
+	RuleOutside    = "outside"    // Library/rpath außerhalb von Homebrew und System
+	RuleUndeclared = "undeclared" // gelinkte Formula fehlt in depends_on
+	RuleParse      = "parse"      // Binary nicht lesbar oder zu groß
+)
+
+// maxBinary: größere Dateien werden nicht in den Speicher gelesen
+const maxBinary = 512 << 20
+
+// maxPerRule wie bei validate
+const maxPerRule = 20
+
+// systemDirs: Libraries dort bringt das Betriebssystem mit
+var systemDirs = []string{"/usr/lib", "/usr/lib32", "/usr/lib64", "/lib", "/lib32", "/lib64", "/System/Library"}
+
+type Options struct {
+	Formula  string   // eigene Libraries zählen nicht als Abhängigkeit
+	Prefix   string   // HOMEBREW_PREFIX der Build-Maschine, löst <prefix>/lib/... auf
+	Cellar   string   // leer = <Prefix>/Cellar
+	Declared []string // depends_on aus brew info; nil = kein Vergleich
+}
+
+type analyzer struct {
+	opts     Options
+	provided map[string]bool // Install Names und Dateinamen im Bottle
+	perRule  map[string]int
+	out      report.Linkage
+}
+
+func (a *analyzer) add(rule string, sev report.Severity, p, format string, args ...any) {
+	a.perRule[rule]++
+	switch n := a.perRule[rule]; {
+	case n <= maxPerRule:
+		a.out.Findings = append(a.out.Findings, report.Finding{Rule: rule, Severity: sev, Path: p, Message: fmt.Sprintf(format, args...)})
+	case n == maxPerRule+1:
+		a.out.Findings = append(a.out.Findings, report.Finding{Rule: rule, Severity: sev, Message: "further findings of this rule omitted"})
+	}
+}
+
+// Analyze liest alle ELF/Mach-O Dateien im Bottle und ordnet ihre Libraries zu:
+// relativ/im Bottle, System, Homebrew (-> Formula) oder außerhalb (Finding).
+func Analyze(bottlePath string, opts Options) (*report.Linkage, error) {
+	if opts.Cellar == "" && opts.Prefix != "" {
+		opts.Cellar = filepath.Join(opts.Prefix, "Cellar")
+	}
+	a := &analyzer{opts: opts, provided: map[string]bool{}, perRule: map[string]int{}}
+	a.out.Files = []report.LinkedFile{}
+
+	err := bottle.Walk(bottlePath, func(hdr *tar.Header, r io.Reader) error {
+		if hdr.Typeflag == tar.TypeSymlink {
+			// libfoo.1.dylib -> libfoo.1.2.dylib zählt ebenfalls als "im Bottle"
+			a.provided[path.Base(hdr.Name)] = true
+			return nil
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size < 4 {
+			return nil
+		}
+		magic := make([]byte, 4)
+		if _, err := io.ReadFull(r, magic); err != nil {
+			return err
+		}
+		kind := Format(magic)
+		if kind == "" {
+			return nil
+		}
+		if hdr.Size > maxBinary {
+			a.add(RuleParse, report.SeverityWarning, hdr.Name, "%d bytes, too large to analyze", hdr.Size)
+			return nil
+		}
+		b := make([]byte, hdr.Size)
+		copy(b, magic)
+		if _, err := io.ReadFull(r, b[4:]); err != nil {
+			return err
+		}
+
+		var lf report.LinkedFile
+		var err error
+		if kind == "elf" {
+			lf, err = parseELF(b)
+		} else {
+			lf, err = parseMachO(b, kind == "fat")
+		}
+		switch {
+		case errors.Is(err, errSkip):
+			return nil
+		case err != nil:
+			a.add(RuleParse, report.SeverityWarning, hdr.Name, "%v", err)
+			return nil
+		}
+		lf.Path = hdr.Name
+		a.provided[path.Base(hdr.Name)] = true
+		if lf.InstallName != "" {
+			a.provided[path.Base(lf.InstallName)] = true
+		}
+		a.out.Files = append(a.out.Files, lf)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.resolve()
+	return &a.out, nil
+}
+
+func (a *analyzer) resolve() {
+	firstUse := map[string]string{} // Formula -> erstes Binary, das sie nutzt
+	for _, lf := range a.out.Files {
+		for _, rp := range lf.RPaths {
+			if !a.known(rp) {
+				a.add(RuleOutside, report.SeverityError, lf.Path, "rpath %s is outside the Homebrew prefix and system paths", rp)
+			}
+		}
+		if lf.Interpreter != "" && !a.known(lf.Interpreter) {
+			a.add(RuleOutside, report.SeverityError, lf.Path, "interpreter %s is outside the Homebrew prefix and system paths", lf.Interpreter)
+		}
+		for _, lib := range lf.Libraries {
+			f, ok := a.formulaOf(lib, lf.RPaths)
+			if !ok {
+				a.add(RuleOutside, report.SeverityError, lf.Path, "links against %s outside the Homebrew prefix and system paths", lib)
+				continue
+			}
+			if f != "" && f != a.opts.Formula {
+				if _, seen := firstUse[f]; !seen {
+					firstUse[f] = lf.Path
+				}
+			}
+		}
+	}
+
+	for f := range firstUse {
+		a.out.Formulae = append(a.out.Formulae, f)
+	}
+	sort.Strings(a.out.Formulae)
+	if a.opts.Declared == nil {
+		return
+	}
+	declared := map[string]bool{}
+	for _, d := range a.opts.Declared {
+		declared[path.Base(d)] = true
+	}
+	for _, f := range a.out.Formulae {
+		if !declared[f] {
+			a.out.Undeclared = append(a.out.Undeclared, f)
+			a.add(RuleUndeclared, report.SeverityWarning, firstUse[f], "links against %s, which is not in depends_on", f)
+		}
+	}
+}
+
+// known: relativ, Homebrew oder System
+func (a *analyzer) known(p string) bool {
+	if relative(p) {
+		return true
+	}
+	if _, ok := a.homebrewRel(p); ok {
+		return true
+	}
+	return system(p)
+}
+
+// formulaOf: Formula, aus der lib kommt ("" = Bottle selbst, System oder nicht zuzuordnen);
+// ok=false: Pfad außerhalb von Homebrew und System.
+func (a *analyzer) formulaOf(lib string, rpaths []string) (string, bool) {
+	switch {
+	case strings.HasPrefix(lib, "@rpath/"):
+		return a.search(strings.TrimPrefix(lib, "@rpath/"), rpaths), true
+	case relative(lib):
+		return "", true
+	case !strings.Contains(lib, "/"):
+		// ELF DT_NEEDED: nur der soname, Suche über rpath
+		return a.search(lib, rpaths), true
+	}
+	if rel, ok := a.homebrewRel(lib); ok {
+		return a.owner(rel), true
+	}
+	return "", system(lib)
+}
+
+// search: name in den Homebrew-rpaths (und <prefix>/lib) auf der Build-Maschine suchen
+func (a *analyzer) search(name string, rpaths []string) string {
+	if a.provided[path.Base(name)] {
+		return ""
+	}
+	dirs := append(slices.Clone(rpaths), "@@HOMEBREW_PREFIX@@/lib")
+	for _, rp := range dirs {
+		rel, ok := a.homebrewRel(rp)
+		if !ok || a.opts.Prefix == "" {
+			continue
+		}
+		cand := path.Join(rel, name)
+		if _, err := os.Stat(a.onDisk(cand)); err == nil {
+			return a.owner(cand)
+		}
+	}
+	return ""
+}
+
+// homebrewRel: Pfad relativ zum Prefix, Cellar-Pfade als "Cellar/..."
+func (a *analyzer) homebrewRel(p string) (string, bool) {
+	switch {
+	case strings.HasPrefix(p, "@@HOMEBREW_CELLAR@@/"):
+		return "Cellar/" + strings.TrimPrefix(p, "@@HOMEBREW_CELLAR@@/"), true
+	case strings.HasPrefix(p, "@@HOMEBREW_PREFIX@@/"):
+		return strings.TrimPrefix(p, "@@HOMEBREW_PREFIX@@/"), true
+	case strings.HasPrefix(p, "@@"):
+		return "", true // andere Platzhalter (@@HOMEBREW_PERL@@ ...)
+	case a.opts.Cellar != "" && strings.HasPrefix(p, a.opts.Cellar+"/"):
+		return "Cellar/" + strings.TrimPrefix(p, a.opts.Cellar+"/"), true
+	case a.opts.Prefix != "" && strings.HasPrefix(p, a.opts.Prefix+"/"):
+		return strings.TrimPrefix(p, a.opts.Prefix+"/"), true
+	}
+	return "", false
+}
+
+// owner: Formula zu Cellar/<f>/..., opt/<f>/..., sonst über Symlinks im Prefix
+func (a *analyzer) owner(rel string) string {
+	parts := strings.SplitN(rel, "/", 3)
+	if len(parts) >= 2 && (parts[0] == "Cellar" || parts[0] == "opt") {
+		return parts[1]
+	}
+	if a.opts.Prefix == "" {
+		return ""
+	}
+	real, err := filepath.EvalSymlinks(a.onDisk(rel))
+	if err != nil {
+		return ""
+	}
+	cellar, err := filepath.EvalSymlinks(a.opts.Cellar)
+	if err != nil {
+		return ""
+	}
+	r, err := filepath.Rel(cellar, real)
+	if err != nil || strings.HasPrefix(r, "..") {
+		return ""
+	}
+	return strings.SplitN(filepath.ToSlash(r), "/", 2)[0]
+}
+
+func (a *analyzer) onDisk(rel string) string {
+	if c, ok := strings.CutPrefix(rel, "Cellar/"); ok {
+		return filepath.Join(a.opts.Cellar, filepath.FromSlash(c))
+	}
+	return filepath.Join(a.opts.Prefix, filepath.FromSlash(rel))
+}
+
+func relative(p string) bool {
+	for _, pre := range []string{"@loader_path", "@executable_path", "$ORIGIN", "${ORIGIN}"} {
+		if strings.HasPrefix(p, pre) {
+			return true
+		}
+	}
+	return false
+}
+
+func system(p string) bool {
+	for _, d := range systemDirs {
+		if p == d || strings.HasPrefix(p, d+"/") {
+			return true
+		}
+	}
+	return false
+}
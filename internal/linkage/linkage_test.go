@@ -0,0 +1,196 @@
+package linkage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// machoDylib: minimales arm64 Mach-O (MH_DYLIB) mit LC_ID_DYLIB, LC_LOAD_DYLIB und LC_RPATH
+func machoDylib(id string, libs, rpaths []string) []byte {
+	var cmds bytes.Buffer
+	ncmds := 0
+	str := func(s string, fixed int) []byte {
+		n := (fixed + len(s) + 1 + 7) &^ 7 // auf 8 Bytes auffüllen
+		b := make([]byte, n-fixed)
+		copy(b, s)
+		return b
+	}
+	dylib := func(cmd uint32, name string) {
+		s := str(name, 24)
+		_ = binary.Write(&cmds, binary.LittleEndian, []uint32{cmd, uint32(24 + len(s)), 24, 2, 0x10000, 0x10000})
+		cmds.Write(s)
+		ncmds++
+	}
+	dylib(lcIDDylib, id)
+	for _, l := range libs {
+		dylib(lcLoadDylib, l)
+	}
+	for _, rp := range rpaths {
+		s := str(rp, 12)
+		_ = binary.Write(&cmds, binary.LittleEndian, []uint32{lcRpath, uint32(12 + len(s)), 12})
+		cmds.Write(s)
+		ncmds++
+	}
+
+	var b bytes.Buffer
+	// mach_header_64: magic, cputype (arm64), cpusubtype, filetype (MH_DYLIB), ncmds, sizeofcmds, flags, reserved
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{0xfeedfacf, 0x0100000c, 0, 6, uint32(ncmds), uint32(cmds.Len()), 0, 0})
+	b.Write(cmds.Bytes())
+	return b.Bytes()
+}
+
+type entry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     []byte
+}
+
+func writeBottle(t *testing.T, entries []entry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0o644, Size: int64(len(e.body))}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(e.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "gov-srt--1.5.4.arm64_sonoma.bottle.tar.gz")
+	if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// fakePrefix: HOMEBREW_PREFIX mit gov-openssl@3 im Cellar, verlinkt nach lib/ und opt/
+func fakePrefix(t *testing.T) string {
+	t.Helper()
+	prefix := t.TempDir()
+	keg := filepath.Join(prefix, "Cellar", "gov-openssl@3", "3.4.0")
+	for _, d := range []string{filepath.Join(keg, "lib"), filepath.Join(prefix, "lib"), filepath.Join(prefix, "opt")} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, lib := range []string{"libssl.3.dylib", "libcrypto.3.dylib", "libssl.so.3"} {
+		if err := os.WriteFile(filepath.Join(keg, "lib", lib), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join("..", "Cellar", "gov-openssl@3", "3.4.0", "lib", lib), filepath.Join(prefix, "lib", lib)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join("..", "Cellar", "gov-openssl@3", "3.4.0"), filepath.Join(prefix, "opt", "gov-openssl@3")); err != nil {
+		t.Fatal(err)
+	}
+	return prefix
+}
+
+func TestAnalyzeRpath(t *testing.T) {
+	prefix := fakePrefix(t)
+	lib := machoDylib("@rpath/libsrt.1.5.dylib",
+		[]string{
+			"@rpath/libssl.3.dylib", // über <prefix>/lib -> Symlink ins Cellar
+			"@rpath/libsrt.1.dylib", // eigener Symlink im Bottle
+			"/usr/lib/libSystem.B.dylib",
+			"@loader_path/../lib/libsrt_helper.dylib",
+		},
+		[]string{"@loader_path/../lib", "/opt/local/lib"},
+	)
+	p := writeBottle(t, []entry{
+		{name: "gov-srt/1.5.4/lib/libsrt.1.5.dylib", body: lib},
+		{name: "gov-srt/1.5.4/lib/libsrt.1.dylib", typeflag: tar.TypeSymlink, linkname: "libsrt.1.5.dylib"},
+		{name: "gov-srt/1.5.4/lib/pkgconfig/srt.pc", body: []byte("prefix=@@HOMEBREW_CELLAR@@/gov-srt/1.5.4\n")},
+	})
+
+	got, err := Analyze(p, Options{Formula: "gov-srt", Prefix: prefix, Declared: []string{}})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(got.Files) != 1 || got.Files[0].InstallName != "@rpath/libsrt.1.5.dylib" {
+		t.Fatalf("files = %+v", got.Files)
+	}
+	if !slices.Equal(got.Formulae, []string{"gov-openssl@3"}) {
+		t.Fatalf("formulae = %v, want [gov-openssl@3]", got.Formulae)
+	}
+	if !slices.Equal(got.Undeclared, []string{"gov-openssl@3"}) {
+		t.Fatalf("undeclared = %v", got.Undeclared)
+	}
+
+	var rules []string
+	for _, f := range got.Findings {
+		rules = append(rules, f.Rule)
+		if f.Rule == RuleOutside && !strings.Contains(f.Message, "/opt/local/lib") {
+			t.Errorf("outside finding for %q", f.Message)
+		}
+	}
+	slices.Sort(rules)
+	if !slices.Equal(rules, []string{RuleOutside, RuleUndeclared}) {
+		t.Fatalf("findings = %+v", got.Findings)
+	}
+}
+
+// ELF DT_NEEDED enthält nur den soname: Suche über DT_RUNPATH und <prefix>/lib
+func TestResolveSoname(t *testing.T) {
+	prefix := fakePrefix(t)
+	tests := []struct {
+		name    string
+		lib     string
+		rpaths  []string
+		want    string
+		wantOut bool // Finding "outside"
+	}{
+		{"via runpath opt", "libcrypto.3.dylib", []string{"@@HOMEBREW_PREFIX@@/opt/gov-openssl@3/lib"}, "gov-openssl@3", false},
+		{"via prefix lib", "libssl.so.3", nil, "gov-openssl@3", false},
+		{"via absolute runpath", "libssl.so.3", []string{filepath.Join(prefix, "lib")}, "gov-openssl@3", false},
+		{"provided by bottle", "libsrt.so.1.5", nil, "", false},
+		{"system", "libc.so.6", nil, "", false},
+		{"absolute outside", "/opt/vendor/lib/libfoo.so", nil, "", true},
+		{"cellar placeholder", "@@HOMEBREW_CELLAR@@/gov-zlib/1.3/lib/libz.1.dylib", nil, "gov-zlib", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &analyzer{
+				opts:     Options{Formula: "gov-srt", Prefix: prefix, Cellar: filepath.Join(prefix, "Cellar")},
+				provided: map[string]bool{"libsrt.so.1.5": true},
+				perRule:  map[string]int{},
+			}
+			a.out.Files = []report.LinkedFile{{Path: "gov-srt/1.5.4/bin/srt-live-transmit", Format: "elf", Libraries: []string{tt.lib}, RPaths: tt.rpaths}}
+			a.resolve()
+
+			var got string
+			if len(a.out.Formulae) > 0 {
+				got = a.out.Formulae[0]
+			}
+			if got != tt.want {
+				t.Errorf("formula = %q, want %q", got, tt.want)
+			}
+			if out := len(a.out.Findings) > 0; out != tt.wantOut {
+				t.Errorf("findings = %+v, want outside = %v", a.out.Findings, tt.wantOut)
+			}
+		})
+	}
+}
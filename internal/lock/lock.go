@@ -0,0 +1,240 @@
+package lock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Lock-Dateien: eine pro Workdir (dist/) und eine pro Homebrew-Prefix
+const FileName = ".gov-bottle.lock"
+
+func WorkdirPath(workdir string) string { return filepath.Join(workdir, FileName) }
+
+// PrefixPath liegt neben den Locks von Homebrew selbst
+func PrefixPath(prefix string) string {
+	return filepath.Join(prefix, "var", "homebrew", "locks", "gov-bottle.lock")
+}
+
+// Holder: Inhalt der Lock-Datei (wer hält den Lock seit wann)
+type Holder struct {
+	PID     int       `json:"pid"`
+	Host    string    `json:"host"`
+	Started time.Time `json:"started"`
+	Command string    `json:"command,omitempty"`
+}
+
+func (h Holder) String() string {
+	return fmt.Sprintf("pid %d on %s since %s", h.PID, h.Host, h.Started.Format(time.RFC3339))
+}
+
+type Options struct {
+	// Timeout: 0 = sofort abbrechen, < 0 = unbegrenzt warten (bis ctx endet)
+	Timeout time.Duration
+	// StaleAfter: Locks anderer Hosts gelten danach als verwaist (0 = nie);
+	// auf dem eigenen Host entscheidet, ob die PID noch läuft.
+	StaleAfter time.Duration
+	// Poll: Intervall beim Warten (default 2s)
+	Poll time.Duration
+	// OnWait wird einmal aufgerufen, bevor gewartet wird
+	OnWait func(Holder)
+	// OnStale wird aufgerufen, bevor ein verwaister Lock entfernt wird
+	OnStale func(Holder)
+}
+
+// ErrLocked: Lock ist belegt und Timeout abgelaufen
+type ErrLocked struct {
+	Path   string
+	Holder Holder
+}
+
+func (e *ErrLocked) Error() string {
+	return fmt.Sprintf("%s is locked by %s", e.Path, e.Holder)
+}
+
+// Lock: gehaltener Advisory-Lock (Datei mit O_EXCL angelegt)
+type Lock struct {
+	Path   string
+	holder Holder
+}
+
+// Acquire legt die Lock-Datei an; ist sie belegt, wird gemäss Options gewartet.
+func Acquire(ctx context.Context, path string, opt Options) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create lock dir: %w", err)
+	}
+	if opt.Poll <= 0 {
+		opt.Poll = 2 * time.Second
+	}
+
+	host, _ := os.Hostname()
+	me := Holder{PID: os.Getpid(), Host: host, Started: time.Now().UTC().Truncate(time.Second), Command: strings.Join(os.Args, " ")}
+	b, err := json.MarshalIndent(me, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var deadline time.Time
+	if opt.Timeout > 0 {
+		deadline = time.Now().Add(opt.Timeout)
+	}
+	waiting := false
+
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, werr := f.Write(append(b, '\n'))
+			cerr := f.Close()
+			if werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("write lock %s: %w", path, werr)
+			}
+			return &Lock{Path: path, holder: me}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create lock %s: %w", path, err)
+		}
+
+		raw, h, err := readLock(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // gerade freigegeben
+		}
+		var stale bool
+		if err != nil {
+			// leer oder halb geschrieben: ein Prozess zwischen O_EXCL und Write, oder er ist dort abgestürzt
+			h = Holder{}
+			stale = corruptExpired(path)
+		} else {
+			stale = isStale(h, host, opt.StaleAfter)
+		}
+
+		if stale {
+			if opt.OnStale != nil {
+				opt.OnStale(h)
+			}
+			if err := removeStale(path, raw); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if opt.Timeout == 0 || (!deadline.IsZero() && time.Now().After(deadline)) {
+			return nil, &ErrLocked{Path: path, Holder: h}
+		}
+		if !waiting {
+			waiting = true
+			if opt.OnWait != nil {
+				opt.OnWait(h)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for lock %s: %w", path, ctx.Err())
+		case <-time.After(opt.Poll):
+		}
+	}
+}
+
+// Release entfernt die Lock-Datei, sofern sie noch uns gehört. Mehrfacher Aufruf ist erlaubt.
+func (l *Lock) Release() error {
+	if l == nil || l.Path == "" {
+		return nil
+	}
+	path := l.Path
+	l.Path = ""
+
+	h, err := Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err == nil && (h.PID != l.holder.PID || h.Host != l.holder.Host || !h.Started.Equal(l.holder.Started)) {
+		return fmt.Errorf("lock %s was taken over by %s", path, h)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Read liest den Holder einer Lock-Datei.
+func Read(path string) (Holder, error) {
+	_, h, err := readLock(path)
+	return h, err
+}
+
+// readLock liefert zusätzlich den Rohinhalt (Vergleich in removeStale).
+func readLock(path string) ([]byte, Holder, error) {
+	var h Holder
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, h, err
+	}
+	if err := json.Unmarshal(b, &h); err != nil {
+		return b, h, fmt.Errorf("parse lock %s: %w", path, err)
+	}
+	return b, h, nil
+}
+
+// Held: true, wenn die Datei existiert und nicht verwaist ist (z.B. für gc)
+func Held(path string, staleAfter time.Duration) (Holder, bool) {
+	h, err := Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, false
+	}
+	if err != nil {
+		return h, !corruptExpired(path)
+	}
+	host, _ := os.Hostname()
+	return h, !isStale(h, host, staleAfter)
+}
+
+// corruptGrace: so lange darf eine Lock-Datei unlesbar sein (Schreiber zwischen O_EXCL und Write);
+// danach ist sie der Rest eines abgestürzten Prozesses.
+const corruptGrace = 30 * time.Second
+
+func corruptExpired(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && time.Since(st.ModTime()) > corruptGrace
+}
+
+// removeStale entfernt den verwaisten Lock nur, wenn er noch derselbe ist, den wir gelesen haben.
+// Zuerst atomar wegbenennen, dann vergleichen: hat ein anderer Prozess den verwaisten Lock
+// inzwischen selbst entfernt und einen neuen angelegt, wird dieser zurückgelegt statt gelöscht.
+func removeStale(path string, seen []byte) error {
+	tmp := fmt.Sprintf("%s.stale-%d-%d", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, tmp); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // ein anderer Prozess war schneller
+		}
+		return fmt.Errorf("remove stale lock %s: %w", path, err)
+	}
+	defer os.Remove(tmp)
+
+	got, err := os.ReadFile(tmp)
+	if err != nil || bytes.Equal(got, seen) {
+		return nil
+	}
+	// Link legt path nur an, wenn er frei ist; hat ihn in der kurzen Lücke schon ein dritter
+	// Prozess genommen, meldet Release des Verdrängten die Übernahme.
+	if err := os.Link(tmp, path); err != nil && !errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("restore lock %s: %w", path, err)
+	}
+	return nil
+}
+
+func isStale(h Holder, host string, staleAfter time.Duration) bool {
+	if h.Host == host && h.PID > 0 {
+		return !processAlive(h.PID)
+	}
+	return staleAfter > 0 && time.Since(h.Started) > staleAfter
+}
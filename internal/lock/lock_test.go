@@ -0,0 +1,300 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// deadPID: grösser als jedes pid_max, existiert also sicher nicht
+const deadPID = 1 << 30
+
+func writeHolder(t *testing.T, path string, h Holder) {
+	t.Helper()
+	b, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", FileName)
+
+	l, err := Acquire(context.Background(), path, Options{})
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	h, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if h.PID != os.Getpid() {
+		t.Fatalf("holder pid = %d, want %d", h.PID, os.Getpid())
+	}
+
+	// zweiter Versuch (gleicher Prozess, PID lebt): sofort belegt
+	_, err = Acquire(context.Background(), path, Options{})
+	var le *ErrLocked
+	if !errors.As(err, &le) || le.Holder.PID != os.Getpid() {
+		t.Fatalf("second Acquire error = %v, want ErrLocked by own pid", err)
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if err := l.Release(); err != nil {
+		t.Fatalf("second Release: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("lock file still there after Release: %v", err)
+	}
+
+	l, err = Acquire(context.Background(), path, Options{})
+	if err != nil {
+		t.Fatalf("Acquire after Release: %v", err)
+	}
+	_ = l.Release()
+}
+
+func TestAcquireWaitsUntilTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	l, err := Acquire(context.Background(), path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release()
+
+	waited := 0
+	start := time.Now()
+	_, err = Acquire(context.Background(), path, Options{
+		Timeout: 50 * time.Millisecond,
+		Poll:    10 * time.Millisecond,
+		OnWait:  func(Holder) { waited++ },
+	})
+	var le *ErrLocked
+	if !errors.As(err, &le) {
+		t.Fatalf("Acquire error = %v, want ErrLocked", err)
+	}
+	if waited != 1 {
+		t.Fatalf("OnWait called %d times, want 1", waited)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("returned before timeout")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Acquire(ctx, path, Options{Timeout: -1, Poll: 10 * time.Millisecond}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire with canceled ctx = %v, want context.Canceled", err)
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no PID liveness check")
+	}
+	const host = "build-01"
+	old := time.Now().Add(-2 * time.Hour)
+
+	tests := []struct {
+		name       string
+		holder     Holder
+		staleAfter time.Duration
+		want       bool
+	}{
+		{"own host, live pid", Holder{PID: os.Getpid(), Host: host, Started: old}, time.Hour, false},
+		{"own host, dead pid", Holder{PID: deadPID, Host: host, Started: time.Now()}, 0, true},
+		{"own host, dead pid ignores age", Holder{PID: deadPID, Host: host, Started: time.Now()}, time.Hour, true},
+		{"other host, fresh", Holder{PID: deadPID, Host: "build-02", Started: time.Now()}, time.Hour, false},
+		{"other host, old", Holder{PID: deadPID, Host: "build-02", Started: old}, time.Hour, true},
+		{"other host, never stale", Holder{PID: deadPID, Host: "build-02", Started: old}, 0, false},
+		{"own host, no pid, old", Holder{Host: host, Started: old}, time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStale(tt.holder, host, tt.staleAfter); got != tt.want {
+				t.Fatalf("isStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcquireRemovesStaleLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no PID liveness check")
+	}
+	host, _ := os.Hostname()
+	tests := []struct {
+		name       string
+		holder     Holder
+		staleAfter time.Duration
+		wantStale  bool
+	}{
+		{"dead pid on own host", Holder{PID: deadPID, Host: host, Started: time.Now()}, 0, true},
+		{"expired on other host", Holder{PID: 1, Host: "elsewhere", Started: time.Now().Add(-time.Hour)}, time.Minute, true},
+		{"live pid on own host", Holder{PID: os.Getpid(), Host: host, Started: time.Now().Add(-time.Hour)}, time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			writeHolder(t, path, tt.holder)
+
+			var stale []Holder
+			l, err := Acquire(context.Background(), path, Options{
+				StaleAfter: tt.staleAfter,
+				OnStale:    func(h Holder) { stale = append(stale, h) },
+			})
+			if !tt.wantStale {
+				var le *ErrLocked
+				if !errors.As(err, &le) {
+					t.Fatalf("Acquire error = %v, want ErrLocked", err)
+				}
+				if len(stale) != 0 {
+					t.Fatalf("OnStale called for a held lock")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Acquire: %v", err)
+			}
+			defer l.Release()
+			if len(stale) != 1 || stale[0].PID != tt.holder.PID {
+				t.Fatalf("OnStale got %v, want holder pid %d", stale, tt.holder.PID)
+			}
+		})
+	}
+}
+
+func TestReleaseAfterTakeover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	l, err := Acquire(context.Background(), path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := Holder{PID: os.Getpid() + 1, Host: "elsewhere", Started: time.Now().UTC()}
+	writeHolder(t, path, other)
+
+	if err := l.Release(); err == nil {
+		t.Fatalf("Release succeeded on a lock held by %s", other)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("foreign lock file removed: %v", err)
+	}
+}
+
+func TestHeld(t *testing.T) {
+	dir := t.TempDir()
+	host, _ := os.Hostname()
+
+	if _, held := Held(filepath.Join(dir, "missing"), time.Hour); held {
+		t.Fatalf("missing lock reported as held")
+	}
+
+	corrupt := filepath.Join(dir, "corrupt")
+	if err := os.WriteFile(corrupt, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, held := Held(corrupt, time.Hour); !held {
+		t.Fatalf("freshly written unreadable lock must count as held")
+	}
+
+	live := filepath.Join(dir, "live")
+	writeHolder(t, live, Holder{PID: os.Getpid(), Host: host, Started: time.Now()})
+	if _, held := Held(live, time.Hour); !held {
+		t.Fatalf("lock of this process not held")
+	}
+}
+
+func TestAcquireCorruptLock(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		age     time.Duration
+		wantOK  bool
+	}{
+		{"empty, just created", "", 0, false},
+		{"half written, just created", `{"pid": 12`, 0, false},
+		{"empty, left by crash", "", 2 * corruptGrace, true},
+		{"half written, left by crash", `{"pid": 12`, 2 * corruptGrace, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			mtime := time.Now().Add(-tt.age)
+			if err := os.Chtimes(path, mtime, mtime); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, held := Held(path, time.Hour); held == tt.wantOK {
+				t.Fatalf("Held = %v, want %v", held, !tt.wantOK)
+			}
+			l, err := Acquire(context.Background(), path, Options{StaleAfter: time.Hour})
+			if !tt.wantOK {
+				var le *ErrLocked
+				if !errors.As(err, &le) {
+					t.Fatalf("Acquire error = %v, want ErrLocked", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Acquire: %v", err)
+			}
+			_ = l.Release()
+		})
+	}
+}
+
+// B hat den verwaisten Lock gelesen, A hat ihn inzwischen ersetzt: B darf As Lock nicht löschen.
+func TestRemoveStaleKeepsReplacedLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		replaced bool
+	}{
+		{"still the stale lock", false},
+		{"replaced by a new holder", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, FileName)
+			writeHolder(t, path, Holder{PID: deadPID, Host: "elsewhere", Started: time.Now().Add(-time.Hour)})
+			seen, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fresh := Holder{PID: os.Getpid(), Host: "elsewhere", Started: time.Now().UTC().Truncate(time.Second)}
+			if tt.replaced {
+				writeHolder(t, path, fresh)
+			}
+
+			if err := removeStale(path, seen); err != nil {
+				t.Fatalf("removeStale: %v", err)
+			}
+
+			h, err := Read(path)
+			if !tt.replaced {
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("stale lock not removed: %v %v", h, err)
+				}
+			} else if err != nil || h.PID != fresh.PID || !h.Started.Equal(fresh.Started) {
+				t.Fatalf("new lock lost: %v %v", h, err)
+			}
+
+			left, _ := filepath.Glob(filepath.Join(dir, FileName+".stale-*"))
+			if len(left) != 0 {
+				t.Fatalf("leftover files: %v", left)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package lock
+
+// ohne Signal-0 Check gilt ein Lock vom eigenen Host immer als gehalten
+func processAlive(int) bool { return true }
@@ -0,0 +1,32 @@
+package metrics
+
+// Metriken der Bottle-Pipeline. CLI und serve teilen sich diese Registry.
+var (
+	Default = NewRegistry()
+
+	BuildsTotal = Default.Counter("gov_bottle_builds_total",
+		"Bottle builds by formula, tag and result (success, failure, cached, resumed).",
+		"formula", "tag", "result")
+
+	UploadsTotal = Default.Counter("gov_bottle_uploads_total",
+		"Uploads to Nexus by formula, tag and result (success, failure, skipped).",
+		"formula", "tag", "result")
+
+	BrewStepDuration = Default.Histogram("gov_bottle_brew_step_duration_seconds",
+		"Duration of brew steps (uninstall, install, bottle).",
+		[]float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200},
+		"step")
+
+	UploadThroughput = Default.Histogram("gov_bottle_upload_throughput_bytes_per_second",
+		"Upload throughput per file.",
+		[]float64{64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, 64 << 20, 256 << 20},
+		"kind")
+
+	BottleSize = Default.Gauge("gov_bottle_bottle_size_bytes",
+		"Size of the last built bottle.",
+		"formula", "tag")
+
+	LastRun = Default.Gauge("gov_bottle_last_run_timestamp_seconds",
+		"Unix time of the last pipeline run by formula and tag.",
+		"formula", "tag")
+)
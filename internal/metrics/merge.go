@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MergeFile addiert die Werte aus einer Textfile (z.B. Metriken eines Job-Subprozesses)
+// in die Registry: Counter und Histogramme werden summiert, Gauges überschrieben.
+// Nicht registrierte Metriken werden ignoriert. Eine fehlende Datei ist kein Fehler.
+func (r *Registry) MergeFile(path string) error {
+	return r.mergeFile(path, true)
+}
+
+// MergeBaseFile: wie MergeFile, aber die Datei ist älter als die Registry (vorheriger CLI-Lauf):
+// Gauges, die in diesem Lauf gesetzt wurden, bleiben; aus der Datei kommen nur die übrigen.
+func (r *Registry) MergeBaseFile(path string) error {
+	return r.mergeFile(path, false)
+}
+
+func (r *Registry) mergeFile(path string, overwriteGauges bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("open metrics file: %w", err)
+	}
+	defer f.Close()
+	return r.mergeText(f, overwriteGauges)
+}
+
+func (r *Registry) MergeText(in io.Reader) error {
+	return r.mergeText(in, true)
+}
+
+func (r *Registry) mergeText(in io.Reader, overwriteGauges bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sc := bufio.NewScanner(in)
+	for n := 1; sc.Scan(); n++ {
+		ln := strings.TrimSpace(sc.Text())
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		name, labels, value, err := parseSample(ln)
+		if err != nil {
+			return fmt.Errorf("metrics line %d: %w", n, err)
+		}
+		r.mergeSample(name, labels, value, overwriteGauges)
+	}
+	return sc.Err()
+}
+
+// mergeSample: Aufrufer hält r.mu
+func (r *Registry) mergeSample(name string, labels map[string]string, v float64, overwriteGauges bool) {
+	if f, ok := r.families[name]; ok && f.kind != kindHistogram {
+		values := labelValues(f, labels)
+		if f.kind == kindGauge && !overwriteGauges && f.has(values) {
+			return
+		}
+		s := f.get(values)
+		if f.kind == kindCounter {
+			s.value += v
+		} else {
+			s.value = v
+		}
+		return
+	}
+
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		base, ok := strings.CutSuffix(name, suffix)
+		if !ok {
+			continue
+		}
+		f, ok := r.families[base]
+		if !ok || f.kind != kindHistogram {
+			return
+		}
+		s := f.get(labelValues(f, labels))
+		switch suffix {
+		case "_sum":
+			s.sum += v
+		case "_count":
+			s.count += uint64(v)
+		case "_bucket":
+			le, err := strconv.ParseFloat(labels["le"], 64)
+			if err != nil {
+				return // +Inf steckt in _count
+			}
+			for i, b := range f.buckets {
+				if b == le {
+					s.cumulative[i] += uint64(v)
+				}
+			}
+		}
+		return
+	}
+}
+
+func labelValues(f *family, labels map[string]string) []string {
+	out := make([]string, len(f.labels))
+	for i, n := range f.labels {
+		out[i] = labels[n]
+	}
+	return out
+}
+
+// parseSample: name{a="b",c="d"} 1.5 [timestamp]
+func parseSample(ln string) (string, map[string]string, float64, error) {
+	labels := map[string]string{}
+
+	i := strings.IndexAny(ln, "{ ")
+	if i < 0 {
+		return "", nil, 0, fmt.Errorf("no value in %q", ln)
+	}
+	name, rest := ln[:i], ln[i:]
+
+	if strings.HasPrefix(rest, "{") {
+		rest = rest[1:]
+		for {
+			rest = strings.TrimLeft(rest, " ,")
+			if strings.HasPrefix(rest, "}") {
+				rest = rest[1:]
+				break
+			}
+			eq := strings.Index(rest, `="`)
+			if eq < 0 {
+				return "", nil, 0, fmt.Errorf("bad labels in %q", ln)
+			}
+			key := strings.TrimSpace(rest[:eq])
+			rest = rest[eq+2:]
+
+			var val strings.Builder
+			j := 0
+			for ; j < len(rest) && rest[j] != '"'; j++ {
+				if rest[j] == '\\' && j+1 < len(rest) {
+					j++
+					if rest[j] == 'n' {
+						val.WriteByte('\n')
+						continue
+					}
+				}
+				val.WriteByte(rest[j])
+			}
+			if j >= len(rest) {
+				return "", nil, 0, fmt.Errorf("unterminated label value in %q", ln)
+			}
+			labels[key] = val.String()
+			rest = rest[j+1:]
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, 0, fmt.Errorf("no value in %q", ln)
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("bad value in %q: %w", ln, err)
+	}
+	return name, labels, v, nil
+}
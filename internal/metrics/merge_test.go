@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestRegistry() (*Registry, *CounterVec, *GaugeVec, *HistogramVec) {
+	r := NewRegistry()
+	c := r.Counter("t_builds_total", "builds", "formula")
+	g := r.Gauge("t_last_run", "last run", "formula")
+	h := r.Histogram("t_duration", "duration", []float64{1, 10}, "step")
+	return r, c, g, h
+}
+
+const previousRun = `# TYPE t_builds_total counter
+t_builds_total{formula="a"} 3
+t_builds_total{formula="b"} 1
+t_last_run{formula="a"} 100
+t_last_run{formula="b"} 200
+t_duration_bucket{step="x",le="1"} 1
+t_duration_bucket{step="x",le="10"} 2
+t_duration_bucket{step="x",le="+Inf"} 2
+t_duration_sum{step="x"} 5.5
+t_duration_count{step="x"} 2
+t_unknown 7
+`
+
+func TestMergeBaseFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		overwrite bool
+		want      []string
+	}{
+		{"base file keeps gauges of this run", false, []string{
+			`t_builds_total{formula="a"} 4`,
+			`t_builds_total{formula="b"} 1`,
+			`t_last_run{formula="a"} 500`,
+			`t_last_run{formula="b"} 200`,
+			`t_duration_bucket{step="x",le="10"} 3`,
+			`t_duration_count{step="x"} 3`,
+		}},
+		{"newer file overwrites gauges", true, []string{
+			`t_builds_total{formula="a"} 4`,
+			`t_last_run{formula="a"} 100`,
+			`t_last_run{formula="b"} 200`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, c, g, h := newTestRegistry()
+			c.Inc("a")
+			g.Set(500, "a")
+			h.Observe(2, "x")
+
+			path := filepath.Join(t.TempDir(), "m.prom")
+			if err := os.WriteFile(path, []byte(previousRun), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			merge := r.MergeBaseFile
+			if tt.overwrite {
+				merge = r.MergeFile
+			}
+			if err := merge(path); err != nil {
+				t.Fatalf("merge: %v", err)
+			}
+
+			var b strings.Builder
+			if err := r.WriteText(&b); err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(b.String(), line+"\n") {
+					t.Errorf("missing %q in\n%s", line, b.String())
+				}
+			}
+			if strings.Contains(b.String(), "t_unknown") {
+				t.Errorf("unregistered metric merged")
+			}
+		})
+	}
+}
+
+func TestMergeMissingFile(t *testing.T) {
+	r, _, _, _ := newTestRegistry()
+	if err := r.MergeBaseFile(filepath.Join(t.TempDir(), "missing.prom")); err != nil {
+		t.Fatalf("missing file: %v", err)
+	}
+}
@@ -13,3 +13,31 @@ func BottleTarGz(formula, version, tag string) string {
 func BottleJSON(formula, version, tag string) string {
 	return Base(formula, version, tag) + ".json"
 }
+
+// SBOM Dateien: <formula>-<version>.<tag>.sbom.cdx.json / .sbom.spdx.json
+const (
+	SBOMCycloneDXSuffix = ".sbom.cdx.json"
+	SBOMSPDXSuffix      = ".sbom.spdx.json"
+)
+
+func SBOMCycloneDX(formula, version, tag string) string {
+	return fmt.Sprintf("%s-%s.%s", formula, version, tag) + SBOMCycloneDXSuffix
+}
+
+func SBOMSPDX(formula, version, tag string) string {
+	return fmt.Sprintf("%s-%s.%s", formula, version, tag) + SBOMSPDXSuffix
+}
+
+// Provenance: <formula>-<version>.<tag>.provenance.intoto.json
+const ProvenanceSuffix = ".provenance.intoto.json"
+
+func Provenance(formula, version, tag string) string {
+	return fmt.Sprintf("%s-%s.%s", formula, version, tag) + ProvenanceSuffix
+}
+
+// Checkpoint für --resume: <formula>-<version>.<tag>.state.json
+const StateSuffix = ".state.json"
+
+func State(formula, version, tag string) string {
+	return fmt.Sprintf("%s-%s.%s", formula, version, tag) + StateSuffix
+}
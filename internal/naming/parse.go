@@ -0,0 +1,46 @@
+package naming
+
+import "strings"
+
+const (
+	KindBottle = "bottle"
+	KindJSON   = "json"
+)
+
+// Parsed ist das Ergebnis von Parse für <formula>-<version>.<tag>.bottle.(tar.gz|json)
+type Parsed struct {
+	Formula string
+	Version string
+	Tag     string
+	Kind    string // KindBottle oder KindJSON
+}
+
+// Parse zerlegt einen Bottle- oder Report-Dateinamen.
+// Formula und Version werden am ersten "-" getrennt, auf das eine Ziffer folgt
+// (gov-srt-1.5.4, gov-openssl@3-3.6.0, gov-ca-certificates-2025-09-09).
+func Parse(file string) (Parsed, bool) {
+	var p Parsed
+	switch {
+	case strings.HasSuffix(file, ".bottle.tar.gz"):
+		p.Kind, file = KindBottle, strings.TrimSuffix(file, ".bottle.tar.gz")
+	case strings.HasSuffix(file, ".bottle.json"):
+		p.Kind, file = KindJSON, strings.TrimSuffix(file, ".bottle.json")
+	default:
+		return Parsed{}, false
+	}
+
+	dot := strings.LastIndexByte(file, '.')
+	if dot <= 0 || dot == len(file)-1 {
+		return Parsed{}, false
+	}
+	p.Tag = file[dot+1:]
+	file = file[:dot]
+
+	for i := 0; i+1 < len(file); i++ {
+		if file[i] == '-' && file[i+1] >= '0' && file[i+1] <= '9' && i > 0 {
+			p.Formula, p.Version = file[:i], file[i+1:]
+			return p, true
+		}
+	}
+	return Parsed{}, false
+}
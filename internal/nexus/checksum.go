@@ -0,0 +1,74 @@
+package nexus
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/hash"
+)
+
+// setChecksumHeaders: Nexus/Artifactory prüfen X-Checksum-*, Proxies/S3 Content-MD5 (base64)
+func setChecksumHeaders(h http.Header, s hash.Sums) {
+	h.Set("X-Checksum-Sha256", s.SHA256)
+	h.Set("X-Checksum-Sha1", s.SHA1)
+	if raw, err := hex.DecodeString(s.MD5); err == nil {
+		h.Set("Content-MD5", base64.StdEncoding.EncodeToString(raw))
+	}
+}
+
+// checksumMessages: Antworten, mit denen Repository-Manager eine abgelehnte Prüfsumme melden (lowercase)
+var checksumMessages = []string{
+	"checksum validation failed", // Nexus
+	"checksum mismatch",          // Nexus/Proxies
+	"checksum policy",            // Artifactory
+	"baddigest",                  // S3: Content-MD5 passt nicht
+	"invaliddigest",              // S3: Content-MD5 ungültig
+}
+
+// checksumRejected: 400/409/412/422, deren Antwort eine abgelehnte Prüfsumme meldet.
+// Andere 4xx (Redeploy verboten, falscher Pfad, ...) bleiben HTTPError.
+func checksumRejected(status int, body string) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnprocessableEntity:
+	default:
+		return false
+	}
+	b := strings.ToLower(body)
+	for _, m := range checksumMessages {
+		if strings.Contains(b, m) {
+			return true
+		}
+	}
+	// Nexus: "Checksum ... does not match"
+	return strings.Contains(b, "checksum") && strings.Contains(b, "does not match")
+}
+
+// IntegrityError: Server hat die Prüfsummen abgelehnt (Status 4xx) oder die Datei hat sich
+// während des Uploads verändert (Status des erfolgreichen PUT).
+type IntegrityError struct {
+	URL    string
+	File   string
+	Status int
+	Sums   hash.Sums
+	Detail string
+
+	Stored  bool  // PUT war 2xx: auf dem Server lag kurz eine Datei mit falschem Inhalt
+	Removed bool  // ... und wurde per DELETE wieder entfernt
+	Cleanup error // DELETE fehlgeschlagen
+}
+
+func (e *IntegrityError) Error() string {
+	s := fmt.Sprintf("integrity check failed: file=%s sha256=%s status=%d: %s",
+		filepath.Base(e.File), e.Sums.SHA256, e.Status, e.Detail)
+	switch {
+	case e.Removed:
+		s += "; remote file deleted"
+	case e.Stored:
+		s += fmt.Sprintf("; REMOTE FILE LEFT IN PLACE (delete %s by hand): %v", e.URL, e.Cleanup)
+	}
+	return s
+}
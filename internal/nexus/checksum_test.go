@@ -0,0 +1,26 @@
+package nexus
+
+import "testing"
+
+func TestChecksumRejected(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{400, "Checksum validation failed for sha256", true},
+		{400, "SHA256 checksum 'abc' does not match computed 'def'", true},
+		{409, "Checksum policy 'server' rejected the upload", true},
+		{400, "<Error><Code>BadDigest</Code></Error>", true},
+		{400, "Repository does not allow updating assets", false},
+		{400, "Invalid path: digest.txt does not match layout", false},
+		{403, "checksum validation failed", false},
+		{500, "checksum mismatch", false},
+		{201, "", false},
+	}
+	for _, tt := range tests {
+		if got := checksumRejected(tt.status, tt.body); got != tt.want {
+			t.Errorf("checksumRejected(%d, %q) = %v, want %v", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// ErrNotFound: der Server hat 404 geliefert.
+var ErrNotFound = errors.New("not found")
+
+// Get lädt eine (kleine) Datei, z.B. einen .bottle.json Report.
+func (u Uploader) Get(ctx context.Context, url, user, pass string) ([]byte, error) {
+	resp, err := u.get(ctx, url, user, pass)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+	return b, nil
+}
+
+// Download lädt url nach filePath. Geschrieben wird zuerst nach <filePath>.part.
+func (u Uploader) Download(ctx context.Context, url, filePath, user, pass string) error {
+	resp, err := u.get(ctx, url, user, pass)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	tmp := filePath + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("download %s: %w", url, err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("close file: %w", err)
+	}
+	if err := os.Rename(tmp, filePath); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
+
+func (u Uploader) get(ctx context.Context, url, user, pass string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if user != "" {
+		req.SetBasicAuth(user, pass)
+	}
+
+	c := u.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, newHTTPError(http.MethodGet, url, "", resp)
+	}
+	return resp, nil
+}
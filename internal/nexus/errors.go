@@ -0,0 +1,65 @@
+package nexus
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// bodyExcerpt: so viel der Fehlerantwort landet im HTTPError
+const bodyExcerpt = 512
+
+// HTTPError: Nexus hat mit einem Status außerhalb 2xx geantwortet.
+// errors.Is(err, ErrNotFound) greift bei 404.
+type HTTPError struct {
+	Method    string
+	URL       string
+	File      string // bei Uploads die lokale Datei
+	Status    int
+	Body      string // Anfang der Antwort
+	Retryable bool   // 408, 429, 5xx: später nochmals versuchen lohnt sich
+}
+
+func newHTTPError(method, url, file string, resp *http.Response) *HTTPError {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	body := strings.TrimSpace(string(b))
+	if len(body) > bodyExcerpt {
+		body = body[:bodyExcerpt] + "..."
+	}
+	return &HTTPError{
+		Method:    method,
+		URL:       url,
+		File:      file,
+		Status:    resp.StatusCode,
+		Body:      body,
+		Retryable: retryable(resp.StatusCode),
+	}
+}
+
+func (e *HTTPError) Error() string {
+	op := "download"
+	switch e.Method {
+	case http.MethodPut:
+		op = "upload"
+	case http.MethodDelete:
+		op = "delete"
+	}
+	s := fmt.Sprintf("%s failed: url=%q status=%d %s", op, e.URL, e.Status, http.StatusText(e.Status))
+	if e.File != "" {
+		s += fmt.Sprintf(" file=%q", filepath.Base(e.File))
+	}
+	if e.Body != "" {
+		s += fmt.Sprintf(" body=%q", e.Body)
+	}
+	return s
+}
+
+func (e *HTTPError) Is(target error) bool {
+	return target == ErrNotFound && e.Status == http.StatusNotFound
+}
+
+func retryable(status int) bool {
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500
+}
@@ -0,0 +1,104 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Asset ist eine Datei im Nexus Raw Repository.
+type Asset struct {
+	Path        string // relativ zum Prefix aus der base URL
+	DownloadURL string
+	Sha256      string
+	Sha1        string
+	Size        int64
+}
+
+// Name: Dateiname ohne Verzeichnis
+func (a Asset) Name() string {
+	return path.Base(a.Path)
+}
+
+type assetPage struct {
+	Items []struct {
+		Path        string            `json:"path"`
+		DownloadURL string            `json:"downloadUrl"`
+		Checksum    map[string]string `json:"checksum"`
+		FileSize    int64             `json:"fileSize"`
+	} `json:"items"`
+	ContinuationToken *string `json:"continuationToken"`
+}
+
+// SplitBase zerlegt https://host/repository/<repo>/<prefix>/ in (https://host, repo, prefix).
+func SplitBase(baseURL string) (root, repo, prefix string, err error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("parse nexus base: %w", err)
+	}
+	p := strings.Trim(u.Path, "/")
+	before, after, ok := strings.Cut("/"+p+"/", "/repository/")
+	if !ok {
+		return "", "", "", fmt.Errorf("nexus base %q has no /repository/<name>/ segment", baseURL)
+	}
+	repo, prefix, _ = strings.Cut(after, "/")
+	if repo == "" {
+		return "", "", "", fmt.Errorf("nexus base %q has no repository name", baseURL)
+	}
+
+	u.Path = strings.TrimRight(before, "/")
+	u.RawQuery, u.Fragment = "", ""
+	return u.String(), repo, strings.Trim(prefix, "/"), nil
+}
+
+// List liefert alle Assets unter der base URL über die Nexus 3 REST API (/service/rest/v1/assets).
+func (u Uploader) List(ctx context.Context, baseURL, user, pass string) ([]Asset, error) {
+	root, repo, prefix, err := SplitBase(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Asset
+	token := ""
+	for {
+		q := url.Values{}
+		q.Set("repository", repo)
+		if token != "" {
+			q.Set("continuationToken", token)
+		}
+
+		b, err := u.Get(ctx, root+"/service/rest/v1/assets?"+q.Encode(), user, pass)
+		if err != nil {
+			return nil, fmt.Errorf("list assets: %w", err)
+		}
+		var page assetPage
+		if err := json.Unmarshal(b, &page); err != nil {
+			return nil, fmt.Errorf("parse asset list: %w", err)
+		}
+
+		for _, it := range page.Items {
+			p := strings.TrimPrefix(it.Path, "/")
+			if prefix != "" {
+				if !strings.HasPrefix(p, prefix+"/") {
+					continue
+				}
+				p = strings.TrimPrefix(p, prefix+"/")
+			}
+			out = append(out, Asset{
+				Path:        p,
+				DownloadURL: it.DownloadURL,
+				Sha256:      it.Checksum["sha256"],
+				Sha1:        it.Checksum["sha1"],
+				Size:        it.FileSize,
+			})
+		}
+
+		if page.ContinuationToken == nil || *page.ContinuationToken == "" {
+			return out, nil
+		}
+		token = *page.ContinuationToken
+	}
+}
@@ -0,0 +1,74 @@
+package nexus
+
+import (
+	"io"
+	"time"
+)
+
+// Progress: Zwischenstand eines Uploads
+type Progress struct {
+	File    string // Dateiname (ohne Verzeichnis)
+	Sent    int64
+	Total   int64
+	Elapsed time.Duration
+	Done    bool // letzter Aufruf nach erfolgreichem Upload
+}
+
+// Rate: Bytes pro Sekunde seit Beginn
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Sent) / p.Elapsed.Seconds()
+}
+
+// Percent: 0..100, -1 wenn die Größe unbekannt ist
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+	return float64(p.Sent) * 100 / float64(p.Total)
+}
+
+// ETA: geschätzte Restzeit, 0 wenn (noch) nicht schätzbar
+func (p Progress) ETA() time.Duration {
+	rate := p.Rate()
+	if rate <= 0 || p.Total <= 0 || p.Sent >= p.Total {
+		return 0
+	}
+	return time.Duration(float64(p.Total-p.Sent) / rate * float64(time.Second))
+}
+
+// progressInterval: so oft ruft der Reader höchstens den Callback auf
+const progressInterval = 200 * time.Millisecond
+
+type progressReader struct {
+	r       io.Reader
+	p       Progress
+	started time.Time
+	last    time.Time
+	fn      func(Progress)
+}
+
+func newProgressReader(r io.Reader, file string, total int64, fn func(Progress)) *progressReader {
+	now := time.Now()
+	return &progressReader{r: r, p: Progress{File: file, Total: total}, started: now, last: now, fn: fn}
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	pr.p.Sent += int64(n)
+	if now := time.Now(); now.Sub(pr.last) >= progressInterval {
+		pr.last = now
+		pr.p.Elapsed = now.Sub(pr.started)
+		pr.fn(pr.p)
+	}
+	return n, err
+}
+
+// done: Abschluss inkl. Warten auf die Antwort des Servers
+func (pr *progressReader) done() {
+	pr.p.Elapsed = time.Since(pr.started)
+	pr.p.Done = true
+	pr.fn(pr.p)
+}
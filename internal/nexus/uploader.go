@@ -6,10 +6,20 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+
+	"gov-brew-bottle-creation/internal/hash"
+	"gov-brew-bottle-creation/internal/ratelimit"
 )
 
 type Uploader struct {
 	Client *http.Client
+
+	// Progress wird während PutFile regelmäßig aufgerufen (optional)
+	Progress func(Progress)
+
+	// Limit: gemeinsames Bandbreiten-Limit aller Uploads (nil = unbegrenzt)
+	Limit *ratelimit.Limiter
 }
 
 func (u Uploader) PutFile(ctx context.Context, url, filePath, user, pass string) error {
@@ -24,7 +34,27 @@ func (u Uploader) PutFile(ctx context.Context, url, filePath, user, pass string)
 	}
 	defer f.Close()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, f)
+	// Die Prüfsummen gehen als Header raus, und Header stehen vor dem Body: ohne Durchlauf vorab
+	// gibt es sie nicht (Trailer werden von Nexus nicht ausgewertet). Der Upload-Stream wird in
+	// einem MultiWriter nochmals mit allen drei Summen gehasht; weichen sie ab, hat sich die Datei
+	// während des Uploads verändert -> IntegrityError.
+	sums, _, err := hash.ReaderSums(f)
+	if err != nil {
+		return fmt.Errorf("checksums: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind file: %w", err)
+	}
+	streamed := hash.NewSummer()
+
+	body := u.Limit.Reader(ctx, io.TeeReader(f, streamed))
+	var pr *progressReader
+	if u.Progress != nil {
+		pr = newProgressReader(body, filepath.Base(filePath), st.Size(), u.Progress)
+		body = pr
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 
@@ -32,6 +62,7 @@ func (u Uploader) PutFile(ctx context.Context, url, filePath, user, pass string)
 
 	req.SetBasicAuth(user, pass)
 	req.Header.Set("Content-Type", "application/octet-stream")
+	setChecksumHeaders(req.Header, sums)
 
 	// Optional bei Proxies/Servern
 	req.ContentLength = st.Size()
@@ -48,9 +79,47 @@ func (u Uploader) PutFile(ctx context.Context, url, filePath, user, pass string)
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return fmt.Errorf("upload failed: url=%q file=%q bytes=%d status=%s body=%q",
-			url, filePath, st.Size(), resp.Status, string(b))
+		he := newHTTPError(http.MethodPut, url, filePath, resp)
+		if checksumRejected(he.Status, he.Body) {
+			return &IntegrityError{URL: url, File: filePath, Status: he.Status, Sums: sums, Detail: he.Body}
+		}
+		return he
+	}
+	if got := streamed.Sums(); got != sums {
+		// Server hat angenommen, was gesendet wurde -> wieder entfernen, sonst liegt dort ein
+		// Artefakt, das zu keiner der angekündigten Prüfsummen passt
+		ie := &IntegrityError{URL: url, File: filePath, Status: resp.StatusCode, Sums: sums, Stored: true,
+			Detail: fmt.Sprintf("file changed during upload (sent sha256 %s)", got.SHA256)}
+		if ie.Cleanup = u.Delete(ctx, url, user, pass); ie.Cleanup == nil {
+			ie.Removed = true
+		}
+		return ie
+	}
+	if pr != nil {
+		pr.done()
 	}
 	return nil
 }
+
+// Delete entfernt url; 404 gilt als erledigt.
+func (u Uploader) Delete(ctx context.Context, url, user, pass string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.SetBasicAuth(user, pass)
+
+	c := u.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode >= 200 && resp.StatusCode <= 299) {
+		return nil
+	}
+	return newHTTPError(http.MethodDelete, url, "", resp)
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/template"
+
+	"gov-brew-bottle-creation/internal/report"
+)
+
+const (
+	TypeSlack = "slack" // Slack / Mattermost incoming webhook
+	TypeTeams = "teams" // Microsoft Teams (Adaptive Card)
+	TypeJSON  = "json"  // generischer JSON POST
+
+	OnAlways  = "always"
+	OnFailure = "failure"
+	OnSuccess = "success"
+)
+
+const defaultTemplate = `gov-bottle {{.Formula}} {{.Version}} ({{.Tag}}): {{.Result}}` +
+	`{{with .Error}}
+error: {{.}}{{end}}` +
+	`{{if and (eq .Result "failure") (not .Error)}}
+exit code {{.ExitCode}}, see logs{{end}}` +
+	`{{with .NexusURLBottle}}
+bottle: {{.}}{{end}}` +
+	`{{with .NexusURLJSON}}
+json: {{.}}{{end}}`
+
+// Notifier: ein Eintrag der Notify-Konfiguration
+type Notifier struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	URL      string `json:"url"`
+	On       string `json:"on,omitempty"`       // always (default), failure, success
+	Template string `json:"template,omitempty"` // text/template über Event
+	Channel  string `json:"channel,omitempty"`  // nur slack/mattermost
+
+	tmpl *template.Template
+}
+
+// Event: Ergebnis eines Runs (Felder des Reports direkt im Template verfügbar)
+type Event struct {
+	report.BottleReport
+	Result   string `json:"result"` // success | failure
+	ExitCode int    `json:"exit_code"`
+}
+
+// NewEvent leitet das Ergebnis aus Report und Exit-Code ab.
+func NewEvent(rep report.BottleReport, exitCode int) Event {
+	ev := Event{BottleReport: rep, Result: OnSuccess, ExitCode: exitCode}
+	if exitCode != 0 || rep.Status == report.StatusFailed {
+		ev.Result = OnFailure
+	}
+	return ev
+}
+
+// Load liest die Notifier-Liste (JSON Array) und prüft Typen, Filter und Templates.
+func Load(path string) ([]Notifier, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read notify config: %w", err)
+	}
+	var ns []Notifier
+	if err := json.Unmarshal(b, &ns); err != nil {
+		return nil, fmt.Errorf("parse notify config %s: %w", path, err)
+	}
+	for i := range ns {
+		if err := ns[i].init(); err != nil {
+			return nil, fmt.Errorf("%s: notifier %d: %w", path, i, err)
+		}
+	}
+	return ns, nil
+}
+
+func (n *Notifier) init() error {
+	switch n.Type {
+	case TypeSlack, TypeTeams, TypeJSON:
+	default:
+		return fmt.Errorf("unknown type %q (slack, teams, json)", n.Type)
+	}
+	if n.URL == "" {
+		return fmt.Errorf("missing url")
+	}
+	switch n.On {
+	case "":
+		n.On = OnAlways
+	case OnAlways, OnFailure, OnSuccess:
+	default:
+		return fmt.Errorf("unknown filter on=%q (always, failure, success)", n.On)
+	}
+	if n.Name == "" {
+		n.Name = n.Type
+	}
+
+	text := n.Template
+	if text == "" {
+		text = defaultTemplate
+	}
+	t, err := template.New(n.Name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("template: %w", err)
+	}
+	n.tmpl = t
+	return nil
+}
+
+// Wants: Filter des Notifiers
+func (n Notifier) Wants(ev Event) bool {
+	return n.On == OnAlways || n.On == ev.Result
+}
+
+// Render: Nachrichtentext aus dem Template
+func (n Notifier) Render(ev Event) (string, error) {
+	if n.tmpl == nil {
+		if err := n.init(); err != nil {
+			return "", err
+		}
+	}
+	var buf bytes.Buffer
+	if err := n.tmpl.Execute(&buf, ev); err != nil {
+		return "", fmt.Errorf("render %s: %w", n.Name, err)
+	}
+	return buf.String(), nil
+}
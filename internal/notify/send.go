@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type Sender struct {
+	Client *http.Client
+}
+
+// Send schickt das Event an alle passenden Notifier; Fehler werden gesammelt, nicht abgebrochen.
+func (s Sender) Send(ctx context.Context, ns []Notifier, ev Event) error {
+	var errs []error
+	for _, n := range ns {
+		if !n.Wants(ev) {
+			continue
+		}
+		if err := s.post(ctx, n, ev); err != nil {
+			errs = append(errs, fmt.Errorf("notify %s: %w", n.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s Sender) post(ctx context.Context, n Notifier, ev Event) error {
+	text, err := n.Render(ev)
+	if err != nil {
+		return err
+	}
+
+	var payload any
+	switch n.Type {
+	case TypeSlack:
+		payload = slackPayload(n, text)
+	case TypeTeams:
+		payload = teamsPayload(ev, text)
+	default:
+		payload = struct {
+			Text string `json:"text"`
+			Event
+		}{text, ev}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	c := s.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("status=%s body=%q", resp.Status, string(b))
+	}
+	return nil
+}
+
+// Slack und Mattermost verstehen beide {"text": ...}
+func slackPayload(n Notifier, text string) map[string]any {
+	p := map[string]any{"text": text}
+	if n.Channel != "" {
+		p["channel"] = n.Channel
+	}
+	return p
+}
+
+// Teams: Adaptive Card als Message-Attachment (Workflows / Incoming Webhook)
+func teamsPayload(ev Event, text string) map[string]any {
+	color := "Good"
+	if ev.Result == OnFailure {
+		color = "Attention"
+	}
+
+	facts := []map[string]string{
+		{"title": "Formula", "value": ev.Formula},
+		{"title": "Version", "value": ev.Version},
+		{"title": "Tag", "value": ev.Tag},
+		{"title": "Result", "value": ev.Result},
+	}
+	if ev.NexusURLBottle != "" {
+		facts = append(facts, map[string]string{"title": "Bottle", "value": ev.NexusURLBottle})
+	}
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body": []any{
+			map[string]any{
+				"type": "TextBlock", "text": fmt.Sprintf("gov-bottle %s: %s", ev.Formula, ev.Result),
+				"weight": "Bolder", "size": "Medium", "color": color,
+			},
+			map[string]any{"type": "TextBlock", "text": text, "wrap": true},
+			map[string]any{"type": "FactSet", "facts": facts},
+		},
+	}
+	return map[string]any{
+		"type": "message",
+		"attachments": []any{
+			map[string]any{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content":     card,
+			},
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// macOS Codenames -> Versionsnummer (für platform.os.version)
+var macOSVersions = map[string]string{
+	"tahoe":    "26",
+	"sequoia":  "15",
+	"sonoma":   "14",
+	"ventura":  "13",
+	"monterey": "12",
+	"big_sur":  "11",
+}
+
+// ImageName wie Homebrew: "@" wird zu "/", "+" wird zu "x".
+func ImageName(formula string) string {
+	n := strings.ToLower(formula)
+	n = strings.ReplaceAll(n, "@", "/")
+	return strings.ReplaceAll(n, "+", "x")
+}
+
+// Repository liefert <namespace>/<image>, z.B. tlchmi/ch-gov-brew/gov-srt
+func Repository(namespace, formula string) string {
+	ns := strings.Trim(namespace, "/")
+	if ns == "" {
+		return ImageName(formula)
+	}
+	return ns + "/" + ImageName(formula)
+}
+
+// GitHubPackagesURL: nur root_urls mit diesem Präfix lädt brew über die OCI Blob-API
+// (GitHubPackages::URL_REGEX); bei jedem anderen Host holt brew "<root_url>/<bottle-datei>".
+const GitHubPackagesURL = "https://ghcr.io/v2/"
+
+var namespaceRe = regexp.MustCompile(`^[\w-]+/[\w-]+(/|$)`)
+
+// CheckNamespace: brew erkennt den root_url nur mit "<owner>/<repo>" ([\w-]) direkt nach /v2/.
+func CheckNamespace(namespace string) error {
+	if !namespaceRe.MatchString(strings.Trim(namespace, "/")) {
+		return fmt.Errorf("oci namespace %q must start with <owner>/<repo> (letters, digits, _ and -) for brew to use the registry", namespace)
+	}
+	return nil
+}
+
+// RootURL ist der root_url für den bottle-Block: immer in der ghcr.io Form, sonst behandelt brew ihn
+// nicht als Registry. Auf die eigene Registry zeigt brew erst mit
+//
+//	HOMEBREW_ARTIFACT_DOMAIN=<registry>           (ersetzt https://ghcr.io/ in der Download-URL)
+//	HOMEBREW_ARTIFACT_DOMAIN_NO_FALLBACK=1        (kein Rückfall auf ghcr.io)
+//	HOMEBREW_DOCKER_REGISTRY_TOKEN=<token>        (optional; ohne schickt brew "Bearer QQ==")
+//
+// Pfad danach wie bei ghcr.io: <root_url>/<image>/blobs/sha256:<sha>.
+func RootURL(namespace string) string {
+	return GitHubPackagesURL + strings.Trim(namespace, "/")
+}
+
+// BrewURL bildet die Download-URL so nach, wie brew sie aus root_url und HOMEBREW_ARTIFACT_DOMAIN baut.
+func BrewURL(rootURL, artifactDomain, formula, sha256 string) string {
+	u := rootURL + "/" + ImageName(formula) + "/blobs/sha256:" + sha256
+	if artifactDomain != "" {
+		u = strings.TrimRight(artifactDomain, "/") + "/" + strings.TrimPrefix(u, "https://ghcr.io/")
+	}
+	return u
+}
+
+// BlobURL liefert die URL des Bottles in der Registry selbst.
+func BlobURL(registry, namespace, formula, sha256 string) string {
+	return BrewURL(RootURL(namespace), registry, formula, sha256)
+}
+
+// VersionTag: OCI Tags erlauben kein "+", Homebrew ersetzt es durch ".".
+func VersionTag(version string) string {
+	return strings.ReplaceAll(version, "+", ".")
+}
+
+// PlatformForTag übersetzt einen Bottle-Tag (arm64_tahoe, x86_64_linux, sonoma, ...) in eine OCI Platform.
+func PlatformForTag(tag string) Platform {
+	arch := "amd64"
+	rest := tag
+	switch {
+	case strings.HasPrefix(tag, "arm64_"):
+		arch, rest = "arm64", strings.TrimPrefix(tag, "arm64_")
+	case strings.HasPrefix(tag, "x86_64_"):
+		rest = strings.TrimPrefix(tag, "x86_64_")
+	}
+
+	if rest == "linux" {
+		return Platform{Architecture: arch, OS: "linux"}
+	}
+
+	p := Platform{Architecture: arch, OS: "darwin"}
+	if v, ok := macOSVersions[rest]; ok {
+		p.OSVersion = "macOS " + v
+	}
+	return p
+}
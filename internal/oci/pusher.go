@@ -0,0 +1,454 @@
+package oci
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pusher schreibt Bottles als OCI Artefakte in eine Registry (Layout wie ghcr.io/homebrew/core).
+type Pusher struct {
+	Client *http.Client
+
+	Registry string // z.B. https://registry.example.ch (http:// für lokale Test-Registry)
+	User     string
+	Pass     string
+
+	token string
+}
+
+// Bottle beschreibt das zu pushende Bottle.
+type Bottle struct {
+	Namespace string // z.B. tlchmi/ch-gov-brew
+	Formula   string
+	Version   string
+	Tag       string
+
+	Path   string
+	Sha256 string
+
+	Tab     []byte // INSTALL_RECEIPT.json (optional)
+	Created time.Time
+}
+
+type Result struct {
+	Repository     string
+	Reference      string
+	ManifestDigest string
+	IndexDigest    string
+	BlobURL        string
+}
+
+// PushBottle lädt Blob + Config hoch, schreibt ein Manifest pro Tag und
+// aktualisiert das Image-Index der Version (bestehende Tags bleiben erhalten).
+func (p *Pusher) PushBottle(ctx context.Context, b Bottle) (Result, error) {
+	repo := Repository(b.Namespace, b.Formula)
+	ref := VersionTag(b.Version)
+	created := b.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+	createdStr := created.UTC().Format(time.RFC3339)
+
+	if err := p.authenticate(ctx, repo); err != nil {
+		return Result{}, err
+	}
+
+	st, err := os.Stat(b.Path)
+	if err != nil {
+		return Result{}, fmt.Errorf("stat bottle: %w", err)
+	}
+	layerDigest := "sha256:" + b.Sha256
+
+	// 1) Layer (das Bottle selbst)
+	if err := p.pushBlobFile(ctx, repo, layerDigest, b.Path, st.Size()); err != nil {
+		return Result{}, fmt.Errorf("push bottle blob: %w", err)
+	}
+
+	// 2) Config; diff_ids sind die Digests der unkomprimierten Layer
+	diffID, err := uncompressedDigest(b.Path)
+	if err != nil {
+		return Result{}, err
+	}
+	platform := PlatformForTag(b.Tag)
+	cfg, err := json.Marshal(imageConfig{
+		Architecture: platform.Architecture,
+		OS:           platform.OS,
+		OSVersion:    platform.OSVersion,
+		Created:      createdStr,
+		RootFS:       rootFS{Type: "layers", DiffIDs: []string{diffID}},
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal config: %w", err)
+	}
+	cfgDigest := digestOf(cfg)
+	if err := p.pushBlobBytes(ctx, repo, cfgDigest, cfg); err != nil {
+		return Result{}, fmt.Errorf("push config blob: %w", err)
+	}
+
+	// 3) Manifest pro Tag
+	tagRef := ref + "." + b.Tag
+	tagAnn := map[string]string{
+		AnnotationRefName:      tagRef,
+		AnnotationBottleDigest: b.Sha256,
+		AnnotationBottleSize:   strconv.FormatInt(st.Size(), 10),
+	}
+	if len(b.Tab) > 0 {
+		tagAnn[AnnotationTab] = compactJSON(b.Tab)
+	}
+
+	manifestAnn := map[string]string{
+		AnnotationCreated: createdStr,
+		AnnotationVersion: b.Version,
+		AnnotationTitle:   b.Formula + " " + b.Version,
+	}
+	for k, v := range tagAnn {
+		manifestAnn[k] = v
+	}
+
+	man := Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeManifest,
+		Config:        Descriptor{MediaType: MediaTypeConfig, Digest: cfgDigest, Size: int64(len(cfg))},
+		Layers: []Descriptor{{
+			MediaType: MediaTypeLayer,
+			Digest:    layerDigest,
+			Size:      st.Size(),
+			Annotations: map[string]string{
+				AnnotationTitle: fmt.Sprintf("%s--%s.%s.bottle.tar.gz", b.Formula, b.Version, b.Tag),
+			},
+		}},
+		Annotations: manifestAnn,
+	}
+	manBytes, err := json.Marshal(man)
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal manifest: %w", err)
+	}
+	manDigest := digestOf(manBytes)
+	if err := p.putManifest(ctx, repo, manDigest, MediaTypeManifest, manBytes); err != nil {
+		return Result{}, fmt.Errorf("push manifest: %w", err)
+	}
+
+	// 4) Index der Version (merge mit bestehendem)
+	idx, err := p.getIndex(ctx, repo, ref)
+	if err != nil {
+		return Result{}, err
+	}
+	if idx == nil {
+		idx = &Index{SchemaVersion: 2, MediaType: MediaTypeIndex}
+	}
+	kept := idx.Manifests[:0]
+	for _, d := range idx.Manifests {
+		if d.Annotations[AnnotationRefName] != tagRef {
+			kept = append(kept, d)
+		}
+	}
+	idx.Manifests = append(kept, Descriptor{
+		MediaType:   MediaTypeManifest,
+		Digest:      manDigest,
+		Size:        int64(len(manBytes)),
+		Platform:    &platform,
+		Annotations: tagAnn,
+	})
+	idx.Annotations = map[string]string{
+		AnnotationRefName:     ref,
+		AnnotationPackageType: "homebrew_bottle",
+		AnnotationCreated:     createdStr,
+		AnnotationVersion:     b.Version,
+		AnnotationTitle:       b.Formula + " " + b.Version,
+		AnnotationDescription: "Homebrew bottle for " + b.Formula,
+	}
+	idxBytes, err := json.Marshal(idx)
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal index: %w", err)
+	}
+	if err := p.putManifest(ctx, repo, ref, MediaTypeIndex, idxBytes); err != nil {
+		return Result{}, fmt.Errorf("push index: %w", err)
+	}
+
+	return Result{
+		Repository:     repo,
+		Reference:      ref,
+		ManifestDigest: manDigest,
+		IndexDigest:    digestOf(idxBytes),
+		BlobURL:        p.base() + "/v2/" + repo + "/blobs/" + layerDigest,
+	}, nil
+}
+
+func (p *Pusher) base() string {
+	return strings.TrimRight(p.Registry, "/")
+}
+
+func (p *Pusher) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return http.DefaultClient
+}
+
+func (p *Pusher) do(req *http.Request) (*http.Response, error) {
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	} else if p.User != "" {
+		req.SetBasicAuth(p.User, p.Pass)
+	}
+	return p.client().Do(req)
+}
+
+// authenticate: GET /v2/ und bei Bearer-Challenge ein Token für push/pull holen.
+// Registries mit Basic-Auth (oder ohne Auth) brauchen nichts weiter.
+func (p *Pusher) authenticate(ctx context.Context, repo string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.base()+"/v2/", nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("registry ping: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return nil
+	}
+	realm, params, ok := parseBearerChallenge(resp.Header.Get("WWW-Authenticate"))
+	if !ok {
+		return nil // Basic-Auth
+	}
+
+	q := url.Values{}
+	if s := params["service"]; s != "" {
+		q.Set("service", s)
+	}
+	q.Set("scope", "repository:"+repo+":pull,push")
+
+	treq, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+q.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("creating token request: %w", err)
+	}
+	if p.User != "" {
+		treq.SetBasicAuth(p.User, p.Pass)
+	}
+	tresp, err := p.client().Do(treq)
+	if err != nil {
+		return fmt.Errorf("token request: %w", err)
+	}
+	defer tresp.Body.Close()
+	if tresp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(tresp.Body, 4096))
+		return fmt.Errorf("token request failed: status=%s body=%q", tresp.Status, string(b))
+	}
+
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(tresp.Body).Decode(&tok); err != nil {
+		return fmt.Errorf("parse token: %w", err)
+	}
+	p.token = tok.Token
+	if p.token == "" {
+		p.token = tok.AccessToken
+	}
+	return nil
+}
+
+func (p *Pusher) blobExists(ctx context.Context, repo, digest string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, p.base()+"/v2/"+repo+"/blobs/"+digest, nil)
+	if err != nil {
+		return false, fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := p.do(req)
+	if err != nil {
+		return false, fmt.Errorf("head blob: %w", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+func (p *Pusher) pushBlobFile(ctx context.Context, repo, digest, filePath string, size int64) error {
+	if ok, err := p.blobExists(ctx, repo, digest); err != nil || ok {
+		return err
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+	return p.uploadBlob(ctx, repo, digest, f, size)
+}
+
+func (p *Pusher) pushBlobBytes(ctx context.Context, repo, digest string, b []byte) error {
+	if ok, err := p.blobExists(ctx, repo, digest); err != nil || ok {
+		return err
+	}
+	return p.uploadBlob(ctx, repo, digest, bytes.NewReader(b), int64(len(b)))
+}
+
+// uploadBlob: monolithischer Upload (POST -> Location, PUT ?digest=)
+func (p *Pusher) uploadBlob(ctx context.Context, repo, digest string, body io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.base()+"/v2/"+repo+"/blobs/uploads/", nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := p.do(req)
+	if err != nil {
+		return fmt.Errorf("start upload: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("start upload: status=%s", resp.Status)
+	}
+
+	loc, err := p.resolveLocation(resp.Header.Get("Location"))
+	if err != nil {
+		return err
+	}
+	q := loc.Query()
+	q.Set("digest", digest)
+	loc.RawQuery = q.Encode()
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPut, loc.String(), body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.ContentLength = size
+
+	resp, err = p.do(req)
+	if err != nil {
+		return fmt.Errorf("upload blob: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("upload blob failed: digest=%s status=%s body=%q", digest, resp.Status, string(b))
+	}
+	return nil
+}
+
+func (p *Pusher) resolveLocation(loc string) (*url.URL, error) {
+	if loc == "" {
+		return nil, fmt.Errorf("registry returned no upload location")
+	}
+	base, err := url.Parse(p.base() + "/")
+	if err != nil {
+		return nil, fmt.Errorf("parse registry url: %w", err)
+	}
+	u, err := base.Parse(loc)
+	if err != nil {
+		return nil, fmt.Errorf("parse upload location %q: %w", loc, err)
+	}
+	return u, nil
+}
+
+func (p *Pusher) putManifest(ctx context.Context, repo, reference, mediaType string, b []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.base()+"/v2/"+repo+"/manifests/"+reference, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", mediaType)
+
+	resp, err := p.do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		rb, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("put manifest failed: ref=%s status=%s body=%q", reference, resp.Status, string(rb))
+	}
+	return nil
+}
+
+// getIndex liefert nil, wenn für die Version noch kein Index existiert.
+func (p *Pusher) getIndex(ctx context.Context, repo, reference string) (*Index, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.base()+"/v2/"+repo+"/manifests/"+reference, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", MediaTypeIndex)
+
+	resp, err := p.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get index: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get index failed: ref=%s status=%s", reference, resp.Status)
+	}
+
+	var idx Index
+	if err := json.NewDecoder(resp.Body).Decode(&idx); err != nil {
+		return nil, fmt.Errorf("parse index: %w", err)
+	}
+	if idx.MediaType != MediaTypeIndex {
+		return nil, fmt.Errorf("ref %s is not an image index (mediaType=%q)", reference, idx.MediaType)
+	}
+	return &idx, nil
+}
+
+// uncompressedDigest: sha256 über den entpackten tar Stream des Bottles
+func uncompressedDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening bottle: %w", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return "", fmt.Errorf("gunzip bottle: %w", err)
+	}
+	defer zr.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, zr); err != nil {
+		return "", fmt.Errorf("gunzip bottle: %w", err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func digestOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func compactJSON(b []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return string(b)
+	}
+	return buf.String()
+}
+
+// parseBearerChallenge: Bearer realm="...",service="...",scope="..."
+func parseBearerChallenge(h string) (string, map[string]string, bool) {
+	const prefix = "bearer "
+	if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	params := map[string]string{}
+	for _, part := range strings.Split(h[len(prefix):], ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		params[strings.ToLower(k)] = strings.Trim(v, `"`)
+	}
+	realm := params["realm"]
+	return realm, params, realm != ""
+}
@@ -0,0 +1,301 @@
+package oci
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry: minimale OCI Distribution API im Speicher (monolithischer Upload, Manifeste per Referenz).
+type fakeRegistry struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte // repo@digest
+	manifests map[string][]byte // repo:reference
+	uploads   int
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := req.URL.Path
+	if p == "/v2/" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	rest, ok := strings.CutPrefix(p, "/v2/")
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	switch {
+	case strings.HasSuffix(rest, "/blobs/uploads/") && req.Method == http.MethodPost:
+		repo := strings.TrimSuffix(rest, "/blobs/uploads/")
+		w.Header().Set("Location", "/v2/"+repo+"/blobs/uploads/session")
+		w.WriteHeader(http.StatusAccepted)
+
+	case strings.HasSuffix(rest, "/blobs/uploads/session") && req.Method == http.MethodPut:
+		repo := strings.TrimSuffix(rest, "/blobs/uploads/session")
+		b, _ := io.ReadAll(req.Body)
+		digest := req.URL.Query().Get("digest")
+		sum := sha256.Sum256(b)
+		if digest != "sha256:"+hex.EncodeToString(sum[:]) {
+			http.Error(w, "digest mismatch", http.StatusBadRequest)
+			return
+		}
+		r.blobs[repo+"@"+digest] = b
+		r.uploads++
+		w.WriteHeader(http.StatusCreated)
+
+	case strings.Contains(rest, "/blobs/"):
+		repo, digest, _ := strings.Cut(rest, "/blobs/")
+		b, ok := r.blobs[repo+"@"+digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(b)
+		}
+
+	case strings.Contains(rest, "/manifests/"):
+		repo, ref, _ := strings.Cut(rest, "/manifests/")
+		key := repo + ":" + ref
+		switch req.Method {
+		case http.MethodPut:
+			b, _ := io.ReadAll(req.Body)
+			r.manifests[key] = b
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			b, ok := r.manifests[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(b)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func writeBottle(t *testing.T, dir string, content string) (string, string, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(content))
+	_ = zw.Close()
+
+	path := filepath.Join(dir, "bottle.tar.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gz := sha256.Sum256(buf.Bytes())
+	raw := sha256.Sum256([]byte(content))
+	return path, hex.EncodeToString(gz[:]), "sha256:" + hex.EncodeToString(raw[:])
+}
+
+func TestPushBottle(t *testing.T) {
+	reg := newFakeRegistry()
+	srv := httptest.NewServer(reg)
+	defer srv.Close()
+
+	path, sum, diffID := writeBottle(t, t.TempDir(), "tar payload")
+	p := &Pusher{Registry: srv.URL, Client: srv.Client()}
+	res, err := p.PushBottle(context.Background(), Bottle{
+		Namespace: "tlchmi/ch-gov-brew",
+		Formula:   "gov-srt",
+		Version:   "1.5.4",
+		Tag:       "arm64_sonoma",
+		Path:      path,
+		Sha256:    sum,
+		Tab:       []byte("{\n  \"homebrew_version\": \"4.4.0\"\n}"),
+	})
+	if err != nil {
+		t.Fatalf("PushBottle: %v", err)
+	}
+
+	repo := Repository("tlchmi/ch-gov-brew", "gov-srt")
+	if _, ok := reg.blobs[repo+"@sha256:"+sum]; !ok {
+		t.Fatalf("bottle blob not uploaded")
+	}
+
+	var man Manifest
+	if err := json.Unmarshal(reg.manifests[repo+":"+res.ManifestDigest], &man); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if len(man.Layers) != 1 || man.Layers[0].Digest != "sha256:"+sum {
+		t.Fatalf("layers = %+v", man.Layers)
+	}
+	if got := man.Annotations[AnnotationTab]; got != `{"homebrew_version":"4.4.0"}` {
+		t.Fatalf("tab annotation = %q", got)
+	}
+
+	var cfg imageConfig
+	if err := json.Unmarshal(reg.blobs[repo+"@"+man.Config.Digest], &cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if len(cfg.RootFS.DiffIDs) != 1 || cfg.RootFS.DiffIDs[0] != diffID {
+		t.Fatalf("diff_ids = %v, want [%s]", cfg.RootFS.DiffIDs, diffID)
+	}
+
+	var idx Index
+	if err := json.Unmarshal(reg.manifests[repo+":"+VersionTag("1.5.4")], &idx); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if len(idx.Manifests) != 1 || idx.Manifests[0].Digest != res.ManifestDigest {
+		t.Fatalf("index manifests = %+v", idx.Manifests)
+	}
+
+	// zweiter Push: vorhandene Blobs werden nicht nochmals hochgeladen
+	uploads := reg.uploads
+	if _, err := p.PushBottle(context.Background(), Bottle{
+		Namespace: "tlchmi/ch-gov-brew", Formula: "gov-srt", Version: "1.5.4", Tag: "arm64_sonoma",
+		Path: path, Sha256: sum,
+	}); err != nil {
+		t.Fatalf("second PushBottle: %v", err)
+	}
+	if reg.uploads != uploads {
+		t.Fatalf("existing blobs uploaded again: %d -> %d", uploads, reg.uploads)
+	}
+}
+
+func TestPushBottleMergesIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string // ref.name der Einträge im bestehenden Index
+		tag      string
+		want     []string
+	}{
+		{"new index", nil, "arm64_sonoma", []string{"1.5.4.arm64_sonoma"}},
+		{"other tag kept", []string{"1.5.4.x86_64_linux"}, "arm64_sonoma", []string{"1.5.4.x86_64_linux", "1.5.4.arm64_sonoma"}},
+		{"same tag replaced", []string{"1.5.4.arm64_sonoma", "1.5.4.sonoma"}, "arm64_sonoma", []string{"1.5.4.sonoma", "1.5.4.arm64_sonoma"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := newFakeRegistry()
+			srv := httptest.NewServer(reg)
+			defer srv.Close()
+
+			repo := Repository("tlchmi/ch-gov-brew", "gov-srt")
+			if tt.existing != nil {
+				idx := Index{SchemaVersion: 2, MediaType: MediaTypeIndex}
+				for _, ref := range tt.existing {
+					idx.Manifests = append(idx.Manifests, Descriptor{
+						MediaType:   MediaTypeManifest,
+						Digest:      "sha256:" + strings.Repeat("0", 64),
+						Annotations: map[string]string{AnnotationRefName: ref},
+					})
+				}
+				b, _ := json.Marshal(idx)
+				reg.manifests[repo+":"+VersionTag("1.5.4")] = b
+			}
+
+			path, sum, _ := writeBottle(t, t.TempDir(), "payload "+tt.name)
+			p := &Pusher{Registry: srv.URL, Client: srv.Client()}
+			if _, err := p.PushBottle(context.Background(), Bottle{
+				Namespace: "tlchmi/ch-gov-brew", Formula: "gov-srt", Version: "1.5.4", Tag: tt.tag,
+				Path: path, Sha256: sum,
+			}); err != nil {
+				t.Fatalf("PushBottle: %v", err)
+			}
+
+			var idx Index
+			if err := json.Unmarshal(reg.manifests[repo+":"+VersionTag("1.5.4")], &idx); err != nil {
+				t.Fatalf("index: %v", err)
+			}
+			var got []string
+			for _, d := range idx.Manifests {
+				got = append(got, d.Annotations[AnnotationRefName])
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("index refs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// brew lädt Bottles nur über die Blob-API, wenn root_url GitHubPackages::URL_REGEX erfüllt;
+// HOMEBREW_ARTIFACT_DOMAIN ersetzt danach https://ghcr.io/ durch die eigene Registry.
+var brewGitHubPackagesRe = regexp.MustCompile(`^https://ghcr\.io/v2/([\w-]+)/([\w-]+)`)
+
+func TestBrewDownloadsPushedBottle(t *testing.T) {
+	reg := newFakeRegistry()
+	srv := httptest.NewServer(reg)
+	defer srv.Close()
+
+	const namespace = "tlchmi/ch-gov-brew"
+	path, sum, _ := writeBottle(t, t.TempDir(), "bottle for brew")
+	p := &Pusher{Registry: srv.URL, Client: srv.Client()}
+	if _, err := p.PushBottle(context.Background(), Bottle{
+		Namespace: namespace, Formula: "gov-openssl@3", Version: "3.4.0", Tag: "arm64_sonoma",
+		Path: path, Sha256: sum,
+	}); err != nil {
+		t.Fatalf("PushBottle: %v", err)
+	}
+
+	rootURL := RootURL(namespace)
+	if !brewGitHubPackagesRe.MatchString(rootURL) {
+		t.Fatalf("root_url %s is not recognised by brew as a registry", rootURL)
+	}
+
+	// wie brew: root_url/<image>/blobs/sha256:<sha>, Domain ersetzt, anonymer Bearer
+	u := BrewURL(rootURL, srv.URL, "gov-openssl@3", sum)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer QQ==")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: status %s", u, resp.Status)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	want, _ := os.ReadFile(path)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("downloaded %d bytes, want the pushed bottle (%d bytes)", len(got), len(want))
+	}
+	if u != BlobURL(srv.URL, namespace, "gov-openssl@3", sum) {
+		t.Fatalf("BlobURL differs from the URL brew requests: %s", u)
+	}
+}
+
+func TestCheckNamespace(t *testing.T) {
+	tests := map[string]bool{
+		"tlchmi/ch-gov-brew":     true,
+		"/tlchmi/ch-gov-brew/":   true,
+		"tlchmi/ch-gov-brew/sub": true,
+		"tlchmi":                 false,
+		"tlchmi/ch.gov":          false,
+		"":                       false,
+	}
+	for ns, ok := range tests {
+		if err := CheckNamespace(ns); (err == nil) != ok {
+			t.Errorf("CheckNamespace(%q) = %v, want ok=%v", ns, err, ok)
+		}
+	}
+}
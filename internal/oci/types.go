@@ -0,0 +1,65 @@
+package oci
+
+const (
+	MediaTypeIndex    = "application/vnd.oci.image.index.v1+json"
+	MediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeConfig   = "application/vnd.oci.image.config.v1+json"
+	MediaTypeLayer    = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+// Annotation keys wie sie Homebrew (brew pr-upload / GitHubPackages) setzt.
+const (
+	AnnotationRefName     = "org.opencontainers.image.ref.name"
+	AnnotationTitle       = "org.opencontainers.image.title"
+	AnnotationCreated     = "org.opencontainers.image.created"
+	AnnotationVersion     = "org.opencontainers.image.version"
+	AnnotationDescription = "org.opencontainers.image.description"
+	AnnotationSource      = "org.opencontainers.image.source"
+	AnnotationPackageType = "com.github.package.type"
+
+	AnnotationBottleDigest = "sh.brew.bottle.digest"
+	AnnotationBottleSize   = "sh.brew.bottle.size"
+	AnnotationTab          = "sh.brew.tab"
+)
+
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	OSVersion    string `json:"os.version,omitempty"`
+}
+
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *Platform         `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+type Index struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Manifests     []Descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+type rootFS struct {
+	Type    string   `json:"type"`
+	DiffIDs []string `json:"diff_ids"`
+}
+
+type imageConfig struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	OSVersion    string `json:"os.version,omitempty"`
+	Created      string `json:"created,omitempty"`
+	RootFS       rootFS `json:"rootfs"`
+}
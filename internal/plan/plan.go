@@ -12,6 +12,7 @@ type Result struct {
 	Report     report.BottleReport
 	BottleName string
 	JSONName   string
+	Err        error // brew info Fehler (z.B. *brew.ExitError), Text auch in Report.Error
 }
 
 // Plan erzeugen den Report + Namen/URLs, ohne Build/Upload
@@ -60,5 +61,6 @@ func Plan(
 		Report:     rep,
 		BottleName: bottleName,
 		JSONName:   jsonName,
+		Err:        verr,
 	}
 }
@@ -0,0 +1,146 @@
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+	PredicateType = "https://slsa.dev/provenance/v1"
+	BuildType     = "https://gov-brew-bottle-creation/gov-bottle/v1"
+)
+
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type ResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   map[string]any       `json:"externalParameters"`
+	InternalParameters   map[string]any       `json:"internalParameters,omitempty"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type Builder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+type BuildMetadata struct {
+	InvocationID string `json:"invocationId,omitempty"`
+	StartedOn    string `json:"startedOn,omitempty"`
+	FinishedOn   string `json:"finishedOn,omitempty"`
+}
+
+type RunDetails struct {
+	Builder  Builder       `json:"builder"`
+	Metadata BuildMetadata `json:"metadata"`
+}
+
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Build enthält alles, was wir über einen Bottle-Build wissen.
+type Build struct {
+	BottleFile   string
+	BottleSha256 string
+
+	Ref string
+	Tag string
+
+	TapName    string // owner/tap
+	TapURL     string // optional (git remote)
+	TapGitHead string
+
+	BrewVersion string
+	Host        string
+	HostOS      string
+
+	Args     []string // Aufruf, wird redacted
+	Started  time.Time
+	Finished time.Time
+}
+
+// New baut ein in-toto Statement mit SLSA v1 Provenance Predicate.
+func New(b Build) Statement {
+	tapURI := b.TapURL
+	if tapURI == "" {
+		tapURI = "brew-tap:" + b.TapName
+	} else if !strings.HasPrefix(tapURI, "git+") {
+		tapURI = "git+" + tapURI
+	}
+
+	dep := ResourceDescriptor{Name: b.TapName, URI: tapURI}
+	if b.TapGitHead != "" {
+		dep.Digest = map[string]string{"gitCommit": b.TapGitHead}
+	}
+
+	return Statement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   b.BottleFile,
+			Digest: map[string]string{"sha256": b.BottleSha256},
+		}},
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			BuildDefinition: BuildDefinition{
+				BuildType: BuildType,
+				ExternalParameters: map[string]any{
+					"ref":  b.Ref,
+					"tag":  b.Tag,
+					"args": RedactArgs(b.Args),
+				},
+				InternalParameters: map[string]any{
+					"brew_version": b.BrewVersion,
+					"host_os":      b.HostOS,
+				},
+				ResolvedDependencies: []ResourceDescriptor{dep},
+			},
+			RunDetails: RunDetails{
+				Builder: Builder{ID: "gov-bottle://" + b.Host},
+				Metadata: BuildMetadata{
+					StartedOn:  formatTime(b.Started),
+					FinishedOn: formatTime(b.Finished),
+				},
+			},
+		},
+	}
+}
+
+func Write(path string, st Statement) error {
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal provenance: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write provenance: %w", err)
+	}
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
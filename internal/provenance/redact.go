@@ -0,0 +1,66 @@
+package provenance
+
+import (
+	"net/url"
+	"strings"
+)
+
+const redacted = "<redacted>"
+
+// Flag-Namen, deren Wert nie in einer Attestation landen darf.
+var secretWords = []string{"pass", "token", "secret", "password"}
+
+func isSecretFlag(name string) bool {
+	name = strings.ToLower(strings.TrimLeft(name, "-"))
+	for _, w := range secretWords {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactArgs ersetzt Werte geheimer Flags ("--nexus-pass x" und "--nexus-pass=x").
+func RedactArgs(args []string) []string {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") {
+			out = append(out, a)
+			continue
+		}
+		if name, _, ok := strings.Cut(a, "="); ok {
+			if isSecretFlag(name) {
+				a = name + "=" + redacted
+			}
+			out = append(out, a)
+			continue
+		}
+		out = append(out, a)
+		if isSecretFlag(a) && i+1 < len(args) {
+			out = append(out, redacted)
+			i++
+		}
+	}
+	return out
+}
+
+// RedactURL entfernt Zugangsdaten (user:token@) und Query aus einer Remote-URL.
+// scp-artige Remotes (git@host:path) enthalten kein Passwort und bleiben wie sie sind;
+// was sich sonst nicht parsen lässt, wird verworfen.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		if strings.Contains(raw, "://") {
+			return ""
+		}
+		return raw
+	}
+	if u.Opaque != "" {
+		return "" // z.B. user:token@host:path, als scheme:opaque geparst
+	}
+	u.User = nil
+	u.RawQuery, u.ForceQuery = "", false
+	u.Fragment, u.RawFragment = "", ""
+	return u.String()
+}
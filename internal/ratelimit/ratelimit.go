@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"gov-brew-bottle-creation/internal/fsutil"
+)
+
+// Limiter: Token Bucket in Bytes pro Sekunde, gemeinsam für alle Uploads eines Runs.
+// Ein nil-Limiter begrenzt nicht.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64 // Bytes/s
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// minBurst: kleinere Häppchen bremsen nur das Lesen, nicht den Durchsatz
+const minBurst = 32 << 10
+
+// New: bytesPerSec <= 0 = unbegrenzt (nil)
+func New(bytesPerSec int64) *Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	burst := float64(bytesPerSec) / 4 // ~250ms Vorrat
+	if burst < minBurst {
+		burst = minBurst
+	}
+	return &Limiter{rate: float64(bytesPerSec), burst: burst, tokens: burst, last: time.Now()}
+}
+
+// Rate in Bytes/s (0 = unbegrenzt)
+func (l *Limiter) Rate() int64 {
+	if l == nil {
+		return 0
+	}
+	return int64(l.rate)
+}
+
+// WaitN wartet, bis n Bytes gesendet werden dürfen. n größer als der Burst ist erlaubt,
+// der Bucket geht dann ins Minus und spätere Aufrufer warten entsprechend länger.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		// nicht gesendet: Tokens zurückgeben
+		l.mu.Lock()
+		l.tokens += float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Reader begrenzt r über l; gelesen wird höchstens ein Burst pro Read.
+func (l *Limiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &reader{ctx: ctx, r: r, l: l}
+}
+
+type reader struct {
+	ctx context.Context
+	r   io.Reader
+	l   *Limiter
+}
+
+func (lr *reader) Read(b []byte) (int, error) {
+	if max := int(lr.l.burst); len(b) > max {
+		b = b[:max]
+	}
+	n, err := lr.r.Read(b)
+	if werr := lr.l.WaitN(lr.ctx, n); werr != nil {
+		return n, werr
+	}
+	return n, err
+}
+
+// ParseRate: "0" / "" = unbegrenzt, sonst Bytes/s wie fsutil.ParseSize, z.B. "512K", "10M", "1.5MiB/s".
+func ParseRate(s string) (int64, error) {
+	n, err := fsutil.ParseSize(strings.TrimSuffix(strings.TrimSpace(s), "/s"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth limit %q (e.g. 512K, 10M)", s)
+	}
+	return n, nil
+}
@@ -7,6 +7,50 @@ const (
 	StatusFailed  Status = "failed"
 )
 
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding: Ergebnis einer Prüfregel; Path ist relativ zum Bottle (leer = ganzes Bottle)
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Errors zählt die Findings mit Severity error.
+func Errors(fs []Finding) int {
+	n := 0
+	for _, f := range fs {
+		if f.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// LinkedFile: Binary oder Library im Bottle mit ihren dynamischen Abhängigkeiten
+type LinkedFile struct {
+	Path        string   `json:"path"`
+	Format      string   `json:"format"`                 // elf, macho
+	InstallName string   `json:"install_name,omitempty"` // LC_ID_DYLIB bzw. DT_SONAME
+	Interpreter string   `json:"interpreter,omitempty"`  // ELF PT_INTERP
+	Libraries   []string `json:"libraries,omitempty"`
+	RPaths      []string `json:"rpaths,omitempty"`
+}
+
+// Linkage: Ergebnis der Linkage-Analyse (Findings blockieren nicht)
+type Linkage struct {
+	Files      []LinkedFile `json:"files"`
+	Formulae   []string     `json:"formulae,omitempty"`   // Formulae, gegen deren Libraries gelinkt wird
+	Undeclared []string     `json:"undeclared,omitempty"` // davon nicht in depends_on
+	Findings   []Finding    `json:"findings,omitempty"`
+}
+
 type BottleReport struct {
 	Ref     string `json:"ref"`
 	Formula string `json:"formula"`
@@ -23,4 +67,36 @@ type BottleReport struct {
 	Error  string `json:"error,omitempty"`
 
 	Sha256 string `json:"sha256,omitempty"`
+
+	OCIRepository     string `json:"oci_repository,omitempty"`
+	OCIManifestDigest string `json:"oci_manifest_digest,omitempty"`
+	OCIBlobURL        string `json:"oci_blob_url,omitempty"`
+
+	SignatureKeyID string `json:"signature_key_id,omitempty"`
+
+	SBOMCycloneDX         string `json:"sbom_cyclonedx,omitempty"`
+	SBOMSPDX              string `json:"sbom_spdx,omitempty"`
+	NexusURLSBOMCycloneDX string `json:"nexus_url_sbom_cyclonedx,omitempty"`
+	NexusURLSBOMSPDX      string `json:"nexus_url_sbom_spdx,omitempty"`
+
+	Provenance         string `json:"provenance,omitempty"`
+	NexusURLProvenance string `json:"nexus_url_provenance,omitempty"`
+
+	// Nexus-Upload des Bottles
+	UploadBytes       int64   `json:"upload_bytes,omitempty"`
+	UploadSeconds     float64 `json:"upload_seconds,omitempty"`
+	UploadBytesPerSec float64 `json:"upload_bytes_per_sec,omitempty"`
+
+	// Prüfung des Tarballs vor dem Upload (Fehler blockieren den Upload)
+	Validation []Finding `json:"validation,omitempty"`
+
+	// dynamische Libraries der Binaries im Bottle
+	Linkage *Linkage `json:"linkage,omitempty"`
+
+	// --reproducible: Bottle normalisiert; --repro-check: "identical" oder "different"
+	Reproducible bool   `json:"reproducible,omitempty"`
+	ReproCheck   string `json:"repro_check,omitempty"`
+
+	CacheKey string `json:"cache_key,omitempty"`
+	CacheHit string `json:"cache_hit,omitempty"` // "dist" oder "nexus", leer wenn gebaut
 }
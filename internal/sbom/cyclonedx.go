@@ -0,0 +1,160 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxLicense struct {
+	Expression string `json:"expression,omitempty"`
+	License    *struct {
+		ID string `json:"id"`
+	} `json:"license,omitempty"`
+}
+
+type cdxComponent struct {
+	Type        string        `json:"type"`
+	BOMRef      string        `json:"bom-ref"`
+	Name        string        `json:"name"`
+	Version     string        `json:"version,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Scope       string        `json:"scope,omitempty"`
+	PURL        string        `json:"purl,omitempty"`
+	Hashes      []cdxHash     `json:"hashes,omitempty"`
+	Licenses    []cdxLicense  `json:"licenses,omitempty"`
+	Properties  []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+type cdxBOM struct {
+	BOMFormat    string `json:"bomFormat"`
+	SpecVersion  string `json:"specVersion"`
+	SerialNumber string `json:"serialNumber"`
+	Version      int    `json:"version"`
+	Metadata     struct {
+		Timestamp string `json:"timestamp"`
+		Tools     struct {
+			Components []cdxComponent `json:"components"`
+		} `json:"tools"`
+		Component cdxComponent `json:"component"`
+	} `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty"`
+}
+
+// WriteCycloneDX schreibt das Dokument als CycloneDX 1.5 JSON.
+func WriteCycloneDX(path string, d *Document) error {
+	m := d.Meta
+	rootRef := purl(m.Formula, m.Version)
+
+	var bom cdxBOM
+	bom.BOMFormat = "CycloneDX"
+	bom.SpecVersion = "1.5"
+	bom.SerialNumber = "urn:uuid:" + d.Serial
+	bom.Version = 1
+	bom.Metadata.Timestamp = d.Created.Format(time.RFC3339)
+	bom.Metadata.Tools.Components = []cdxComponent{{Type: "application", BOMRef: "tool:gov-bottle", Name: "gov-bottle"}}
+
+	root := cdxComponent{
+		Type:        "application",
+		BOMRef:      rootRef,
+		Name:        m.Formula,
+		Version:     m.Version,
+		Description: m.Description,
+		PURL:        rootRef + "?tag=" + m.Tag,
+		Properties:  []cdxProperty{{Name: "gov-bottle:tag", Value: m.Tag}},
+	}
+	if m.BottleSha256 != "" {
+		root.Hashes = []cdxHash{{Alg: "SHA-256", Content: m.BottleSha256}}
+	}
+	if m.DeclaredLicense != "" {
+		root.Licenses = []cdxLicense{{Expression: m.DeclaredLicense}}
+	}
+	bom.Metadata.Component = root
+
+	for _, f := range d.Files {
+		c := cdxComponent{
+			Type:   "file",
+			BOMRef: "file:" + f.Path,
+			Name:   f.Path,
+			Properties: []cdxProperty{
+				{Name: "gov-bottle:size", Value: strconv.FormatInt(f.Size, 10)},
+				{Name: "gov-bottle:mode", Value: f.Mode},
+			},
+		}
+		if f.Sha256 != "" {
+			c.Hashes = []cdxHash{{Alg: "SHA-256", Content: f.Sha256}, {Alg: "SHA-1", Content: f.Sha1}}
+		}
+		if f.LinkTarget != "" {
+			c.Properties = append(c.Properties, cdxProperty{Name: "gov-bottle:symlink", Value: f.LinkTarget})
+		}
+		if f.License != "" {
+			if f.License == LicenseUnknown {
+				c.Properties = append(c.Properties, cdxProperty{Name: "gov-bottle:license-file", Value: "unrecognized"})
+			} else {
+				c.Licenses = []cdxLicense{{License: &struct {
+					ID string `json:"id"`
+				}{ID: f.License}}}
+			}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+
+	rootDep := cdxDependency{Ref: rootRef}
+	for _, dep := range m.Dependencies {
+		ref := purl(dep.Name, "")
+		scope := "required"
+		if dep.Kind == "build" {
+			scope = "excluded"
+		}
+		bom.Components = append(bom.Components, cdxComponent{
+			Type:       "library",
+			BOMRef:     ref,
+			Name:       dep.Name,
+			Scope:      scope,
+			PURL:       ref,
+			Properties: []cdxProperty{{Name: "gov-bottle:dependency-kind", Value: dep.Kind}},
+		})
+		rootDep.DependsOn = append(rootDep.DependsOn, ref)
+	}
+	bom.Dependencies = []cdxDependency{rootDep}
+
+	return writeJSON(path, bom)
+}
+
+// purl: Homebrew hat keinen eigenen purl-Typ, daher generic.
+func purl(name, version string) string {
+	p := "pkg:generic/" + name
+	if version != "" {
+		p += "@" + version
+	}
+	return p
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal sbom: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write sbom: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package sbom
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+const LicenseUnknown = "LicenseRef-unknown"
+
+var reLicenseFile = regexp.MustCompile(`(?i)^(licen[cs]e|copying|notice|copyright|unlicense)([.-].*)?$`)
+
+func isLicenseFile(rel string) bool {
+	if strings.HasPrefix(rel, "share/licenses/") {
+		return true
+	}
+	return reLicenseFile.MatchString(path.Base(rel))
+}
+
+// licenseSniffer merkt sich die ersten 8 KiB einer License-Datei.
+type licenseSniffer struct {
+	buf []byte
+}
+
+func (l *licenseSniffer) Write(p []byte) (int, error) {
+	const max = 8 * 1024
+	if rest := max - len(l.buf); rest > 0 {
+		if len(p) < rest {
+			rest = len(p)
+		}
+		l.buf = append(l.buf, p[:rest]...)
+	}
+	return len(p), nil
+}
+
+// Reihenfolge ist wichtig: spezifischere Texte zuerst (LGPL vor GPL usw.).
+var licenseRules = []struct {
+	id  string
+	all []string
+}{
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"LGPL-3.0-only", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1-only", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0-only", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0-only", []string{"gnu general public license", "version 2"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software"}},
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+	{"Zlib", []string{"this software is provided 'as-is'", "altered source versions must be plainly marked"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+func detectLicense(b []byte) string {
+	text := strings.Join(strings.Fields(strings.ToLower(string(b))), " ")
+	for _, r := range licenseRules {
+		ok := true
+		for _, s := range r.all {
+			if !strings.Contains(text, s) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return r.id
+		}
+	}
+	return LicenseUnknown
+}
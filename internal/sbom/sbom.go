@@ -0,0 +1,129 @@
+package sbom
+
+import (
+	"archive/tar"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"gov-brew-bottle-creation/internal/bottle"
+)
+
+// Dependency aus brew info (declared, nicht resolved)
+type Dependency struct {
+	Name string
+	Kind string // "runtime" oder "build"
+}
+
+// Meta: Angaben zum Bottle, die nicht aus dem Tarball kommen.
+type Meta struct {
+	Formula      string
+	Version      string
+	Tag          string
+	BottleFile   string
+	BottleSha256 string
+	DownloadURL  string
+
+	Description     string
+	Homepage        string
+	DeclaredLicense string
+	Dependencies    []Dependency
+}
+
+type File struct {
+	Path       string // relativ zu <formula>/<version>/
+	Type       string // "file" oder "symlink"
+	Size       int64
+	Mode       string // oktal, z.B. "0755"
+	Sha256     string
+	Sha1       string
+	LinkTarget string
+
+	License string // SPDX ID, nur bei erkannten License-Dateien
+}
+
+type Document struct {
+	Meta    Meta
+	Created time.Time
+	Serial  string // UUID
+	Files   []File
+}
+
+// Licenses liefert die erkannten SPDX IDs der gebündelten License-Dateien (sortiert, eindeutig).
+func (d *Document) Licenses() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, f := range d.Files {
+		if f.License != "" && !seen[f.License] {
+			seen[f.License] = true
+			out = append(out, f.License)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Generate streamt das Bottle und erfasst jede Datei mit Grösse, Mode und Hashes.
+func Generate(bottlePath string, meta Meta) (*Document, error) {
+	doc := &Document{Meta: meta, Created: time.Now().UTC(), Serial: newUUID()}
+
+	err := bottle.Walk(bottlePath, func(hdr *tar.Header, r io.Reader) error {
+		rel := bottle.StripPrefix(hdr.Name)
+		if rel == "" {
+			return nil
+		}
+
+		f := File{Path: rel, Mode: fmt.Sprintf("%04o", hdr.Mode&0o7777)}
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			f.Type = "file"
+		case tar.TypeSymlink:
+			f.Type = "symlink"
+			f.LinkTarget = hdr.Linkname
+			doc.Files = append(doc.Files, f)
+			return nil
+		default:
+			return nil // Verzeichnisse etc.
+		}
+
+		h256 := sha256.New()
+		h1 := sha1.New()
+		lic := &licenseSniffer{}
+		var w io.Writer = io.MultiWriter(h256, h1)
+		if isLicenseFile(rel) {
+			w = io.MultiWriter(w, lic)
+		}
+		n, err := io.Copy(w, r)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", rel, err)
+		}
+
+		f.Size = n
+		f.Sha256 = hex.EncodeToString(h256.Sum(nil))
+		f.Sha1 = hex.EncodeToString(h1.Sum(nil))
+		if isLicenseFile(rel) {
+			f.License = detectLicense(lic.buf)
+		}
+		doc.Files = append(doc.Files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(doc.Files, func(i, j int) bool { return doc.Files[i].Path < doc.Files[j].Path })
+	return doc, nil
+}
+
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 // v4
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
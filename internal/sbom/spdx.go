@@ -0,0 +1,181 @@
+package sbom
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxPackage struct {
+	Name                    string `json:"name"`
+	SPDXID                  string `json:"SPDXID"`
+	VersionInfo             string `json:"versionInfo,omitempty"`
+	DownloadLocation        string `json:"downloadLocation"`
+	Homepage                string `json:"homepage,omitempty"`
+	Description             string `json:"description,omitempty"`
+	FilesAnalyzed           bool   `json:"filesAnalyzed"`
+	PackageVerificationCode *struct {
+		Value string `json:"packageVerificationCodeValue"`
+	} `json:"packageVerificationCode,omitempty"`
+	Checksums            []spdxChecksum `json:"checksums,omitempty"`
+	LicenseConcluded     string         `json:"licenseConcluded"`
+	LicenseDeclared      string         `json:"licenseDeclared"`
+	LicenseInfoFromFiles []string       `json:"licenseInfoFromFiles,omitempty"`
+	CopyrightText        string         `json:"copyrightText"`
+}
+
+type spdxFile struct {
+	FileName           string         `json:"fileName"`
+	SPDXID             string         `json:"SPDXID"`
+	Checksums          []spdxChecksum `json:"checksums"`
+	LicenseConcluded   string         `json:"licenseConcluded"`
+	LicenseInfoInFiles []string       `json:"licenseInfoInFiles,omitempty"`
+	CopyrightText      string         `json:"copyrightText"`
+	Comment            string         `json:"comment,omitempty"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+type spdxDoc struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DataLicense       string `json:"dataLicense"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	CreationInfo      struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	Packages      []spdxPackage      `json:"packages"`
+	Files         []spdxFile         `json:"files"`
+	Relationships []spdxRelationship `json:"relationships"`
+}
+
+var reSPDXIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// WriteSPDX schreibt das Dokument als SPDX 2.3 JSON.
+func WriteSPDX(path string, d *Document) error {
+	m := d.Meta
+
+	var doc spdxDoc
+	doc.SPDXVersion = "SPDX-2.3"
+	doc.DataLicense = "CC0-1.0"
+	doc.SPDXID = "SPDXRef-DOCUMENT"
+	doc.Name = fmt.Sprintf("%s-%s.%s", m.Formula, m.Version, m.Tag)
+	doc.DocumentNamespace = "urn:uuid:" + d.Serial
+	doc.CreationInfo.Created = d.Created.Format(time.RFC3339)
+	doc.CreationInfo.Creators = []string{"Tool: gov-bottle"}
+
+	rootID := spdxID("Package", m.Formula)
+	root := spdxPackage{
+		Name:             m.Formula,
+		SPDXID:           rootID,
+		VersionInfo:      m.Version,
+		DownloadLocation: orNoAssertion(m.DownloadURL),
+		Homepage:         m.Homepage,
+		Description:      m.Description,
+		FilesAnalyzed:    true,
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  orNoAssertion(m.DeclaredLicense),
+		CopyrightText:    "NOASSERTION",
+	}
+	if m.BottleSha256 != "" {
+		root.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: m.BottleSha256}}
+	}
+	for _, l := range d.Licenses() {
+		if l != LicenseUnknown {
+			root.LicenseInfoFromFiles = append(root.LicenseInfoFromFiles, l)
+		}
+	}
+	if len(root.LicenseInfoFromFiles) == 0 {
+		root.LicenseInfoFromFiles = []string{"NOASSERTION"}
+	}
+
+	doc.Relationships = append(doc.Relationships, spdxRelationship{Element: doc.SPDXID, Type: "DESCRIBES", Related: rootID})
+
+	var sha1s []string
+	for i, f := range d.Files {
+		if f.Type != "file" {
+			continue // SPDX Files brauchen Checksums, Symlinks stehen in der CycloneDX Variante
+		}
+		id := fmt.Sprintf("SPDXRef-File-%d", i+1)
+		sf := spdxFile{
+			FileName: "./" + f.Path,
+			SPDXID:   id,
+			Checksums: []spdxChecksum{
+				{Algorithm: "SHA1", ChecksumValue: f.Sha1},
+				{Algorithm: "SHA256", ChecksumValue: f.Sha256},
+			},
+			LicenseConcluded: "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+			Comment:          fmt.Sprintf("size=%d mode=%s", f.Size, f.Mode),
+		}
+		if f.License != "" {
+			// nicht erkannte Texte wären ein LicenseRef ohne extractedText
+			sf.LicenseInfoInFiles = []string{"NOASSERTION"}
+			if f.License != LicenseUnknown {
+				sf.LicenseInfoInFiles = []string{f.License}
+			}
+		}
+		doc.Files = append(doc.Files, sf)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: rootID, Type: "CONTAINS", Related: id})
+		sha1s = append(sha1s, f.Sha1)
+	}
+	root.PackageVerificationCode = &struct {
+		Value string `json:"packageVerificationCodeValue"`
+	}{Value: verificationCode(sha1s)}
+	doc.Packages = append(doc.Packages, root)
+
+	for _, dep := range m.Dependencies {
+		id := spdxID("Package", dep.Name)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:             dep.Name,
+			SPDXID:           id,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+		})
+		rel := "DEPENDS_ON"
+		if dep.Kind == "build" {
+			rel = "BUILD_DEPENDENCY_OF"
+			doc.Relationships = append(doc.Relationships, spdxRelationship{Element: id, Type: rel, Related: rootID})
+			continue
+		}
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: rootID, Type: rel, Related: id})
+	}
+
+	return writeJSON(path, doc)
+}
+
+// verificationCode nach SPDX 2.3 §7.9: SHA1 über die sortierten, konkatenierten File-SHA1s.
+func verificationCode(sha1s []string) string {
+	sorted := append([]string(nil), sha1s...)
+	sort.Strings(sorted)
+	sum := sha1.Sum([]byte(strings.Join(sorted, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+func spdxID(kind, name string) string {
+	return "SPDXRef-" + kind + "-" + reSPDXIDChars.ReplaceAllString(name, "-")
+}
+
+func orNoAssertion(s string) string {
+	if s == "" {
+		return "NOASSERTION"
+	}
+	return s
+}
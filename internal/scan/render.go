@@ -0,0 +1,67 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+func WriteJSON(w io.Writer, r Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func WriteTable(w io.Writer, r Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "FORMULA\tVERSION\t%s\n", strings.ToUpper(strings.Join(r.Tags, "\t")))
+	for _, row := range r.Rows {
+		if row.Error != "" {
+			fmt.Fprintf(tw, "%s\t?\terror: %s\n", row.Formula, row.Error)
+			continue
+		}
+		cells := make([]string, 0, len(r.Tags))
+		for _, t := range r.Tags {
+			cells = append(cells, string(row.Cells[t].Status))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", row.Formula, row.Version, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+var markdownIcon = map[Status]string{
+	StatusCurrent:    "✅ current",
+	StatusOutdated:   "🕒 outdated",
+	StatusMissing:    "❌ missing",
+	StatusMismatched: "⚠️ mismatched",
+}
+
+func WriteMarkdown(w io.Writer, r Result) error {
+	var b strings.Builder
+	b.WriteString("| Formula | Version |")
+	for _, t := range r.Tags {
+		b.WriteString(" " + t + " |")
+	}
+	b.WriteString("\n|---|---|" + strings.Repeat("---|", len(r.Tags)) + "\n")
+
+	for _, row := range r.Rows {
+		if row.Error != "" {
+			fmt.Fprintf(&b, "| %s | ? | error: %s |%s\n", row.Formula, row.Error, strings.Repeat(" |", max(len(r.Tags)-1, 0)))
+			continue
+		}
+		fmt.Fprintf(&b, "| %s | %s |", row.Formula, row.Version)
+		for _, t := range r.Tags {
+			c := row.Cells[t]
+			cell := markdownIcon[c.Status]
+			if c.Detail != "" {
+				cell += " (" + c.Detail + ")"
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
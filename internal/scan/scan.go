@@ -0,0 +1,193 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/formula"
+	"gov-brew-bottle-creation/internal/naming"
+	"gov-brew-bottle-creation/internal/nexus"
+	"gov-brew-bottle-creation/internal/report"
+)
+
+type Status string
+
+const (
+	StatusCurrent    Status = "current"
+	StatusOutdated   Status = "outdated"
+	StatusMissing    Status = "missing"
+	StatusMismatched Status = "mismatched"
+)
+
+type Cell struct {
+	Status      Status `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+	BlockSha256 string `json:"block_sha256,omitempty"`
+	DistSha256  string `json:"dist_sha256,omitempty"`
+	NexusSha256 string `json:"nexus_sha256,omitempty"`
+}
+
+type Row struct {
+	Formula string          `json:"formula"`
+	Version string          `json:"version"`
+	Error   string          `json:"error,omitempty"`
+	Cells   map[string]Cell `json:"cells"`
+}
+
+type Result struct {
+	Tags []string `json:"tags"`
+	Rows []Row    `json:"rows"`
+}
+
+// Options für Run. Nexus ist optional (nil = nur dist/).
+type Options struct {
+	TapWorkdir string
+	Workdir    string
+	Tags       []string // zusätzliche Tags (z.B. --tag), auch wenn nirgends ein Bottle existiert
+	Nexus      []nexus.Asset
+}
+
+// artifact: sha256 pro Version für (formula, tag)
+type artifacts map[string]map[string]map[string]string // formula -> tag -> version -> sha256
+
+func (a artifacts) add(f, tag, version, sha string) {
+	if a[f] == nil {
+		a[f] = map[string]map[string]string{}
+	}
+	if a[f][tag] == nil {
+		a[f][tag] = map[string]string{}
+	}
+	a[f][tag][version] = sha
+}
+
+// Run erstellt die formula × tag Matrix.
+func Run(opts Options) (Result, error) {
+	paths, err := formula.ListFormulae(opts.TapWorkdir)
+	if err != nil {
+		return Result{}, err
+	}
+
+	dist, err := distReports(opts.Workdir)
+	if err != nil {
+		return Result{}, err
+	}
+	remote := artifacts{}
+	for _, a := range opts.Nexus {
+		if p, ok := naming.Parse(a.Name()); ok && p.Kind == naming.KindBottle && a.Sha256 != "" {
+			remote.add(p.Formula, p.Tag, p.Version, a.Sha256)
+		}
+	}
+
+	tagSet := map[string]bool{}
+	for _, t := range opts.Tags {
+		tagSet[t] = true
+	}
+
+	infos := make([]formula.Info, 0, len(paths))
+	var rows []Row
+	for _, p := range paths {
+		fi, err := formula.ParseFile(p)
+		if err != nil {
+			// fi ist bei Fehlern leer: Zeile nach der Datei benennen
+			name := strings.TrimSuffix(filepath.Base(p), ".rb")
+			rows = append(rows, Row{Formula: name, Error: err.Error(), Cells: map[string]Cell{}})
+			continue
+		}
+		infos = append(infos, fi)
+		for t := range fi.Shas {
+			tagSet[t] = true
+		}
+		for t := range dist[fi.Name] {
+			tagSet[t] = true
+		}
+		for t := range remote[fi.Name] {
+			tagSet[t] = true
+		}
+	}
+
+	tags := make([]string, 0, len(tagSet))
+	for t := range tagSet {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+
+	for _, fi := range infos {
+		row := Row{Formula: fi.Name, Version: fi.Version, Cells: map[string]Cell{}}
+		for _, t := range tags {
+			row.Cells[t] = classify(fi.Shas[t], fi.Version, dist[fi.Name][t], remote[fi.Name][t])
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Formula < rows[j].Formula })
+
+	return Result{Tags: tags, Rows: rows}, nil
+}
+
+func classify(block, version string, dist, remote map[string]string) Cell {
+	c := Cell{BlockSha256: block, DistSha256: dist[version], NexusSha256: remote[version]}
+
+	have := c.DistSha256
+	if have == "" {
+		have = c.NexusSha256
+	}
+
+	switch {
+	case c.DistSha256 != "" && c.NexusSha256 != "" && c.DistSha256 != c.NexusSha256:
+		c.Status, c.Detail = StatusMismatched, "dist and nexus sha256 differ"
+	case have != "" && block == have:
+		c.Status = StatusCurrent
+	case have != "" && block != "":
+		c.Status, c.Detail = StatusMismatched, "bottle block sha256 differs from built bottle"
+	case have != "":
+		c.Status, c.Detail = StatusOutdated, "bottle built, formula bottle block has no entry"
+	case block != "":
+		c.Status, c.Detail = StatusOutdated, "bottle block references a bottle not found for "+version
+		if v := olderVersion(block, dist, remote); v != "" {
+			c.Detail = "bottle block still points to " + v
+		}
+	case len(dist) > 0 || len(remote) > 0:
+		c.Status, c.Detail = StatusOutdated, "only bottles for other versions"
+	default:
+		c.Status = StatusMissing
+	}
+	return c
+}
+
+func olderVersion(sha string, maps ...map[string]string) string {
+	for _, m := range maps {
+		for v, s := range m {
+			if s == sha {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// distReports liest alle *.bottle.json mit sha256 aus dem workdir.
+func distReports(workdir string) (artifacts, error) {
+	out := artifacts{}
+	if workdir == "" {
+		return out, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(workdir, "*.bottle.json"))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+	for _, p := range matches {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		var rep report.BottleReport
+		if err := json.Unmarshal(b, &rep); err != nil || rep.Sha256 == "" {
+			continue
+		}
+		out.add(rep.Formula, rep.Tag, rep.Version, rep.Sha256)
+	}
+	return out, nil
+}
@@ -0,0 +1,37 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunNamesUnreadableFormula(t *testing.T) {
+	tap := t.TempDir()
+	dir := filepath.Join(tap, "Formula", "s")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ok := "class GovSrt < Formula\n  url \"https://example.org/srt-1.5.4.tar.gz\"\nend\n"
+	if err := os.WriteFile(filepath.Join(dir, "gov-srt.rb"), []byte(ok), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// ReadFile schlägt fehl
+	if err := os.Symlink(filepath.Join(tap, "missing"), filepath.Join(dir, "gov-broken.rb")); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Run(Options{TapWorkdir: tap, Tags: []string{"arm64_sonoma"}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var broken *Row
+	for i := range res.Rows {
+		if res.Rows[i].Error != "" {
+			broken = &res.Rows[i]
+		}
+	}
+	if broken == nil || broken.Formula != "gov-broken" {
+		t.Fatalf("rows = %+v, want an error row named gov-broken", res.Rows)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// jobView: Job plus die Reports der bereits gelaufenen Refs.
+type jobView struct {
+	Job
+	Reports []report.BottleReport `json:"reports,omitempty"`
+}
+
+// Handler liefert die REST API:
+//
+//	POST /jobs                 Job anlegen (JobRequest)
+//	GET  /jobs                 alle Jobs
+//	GET  /jobs/{id}            Status + Reports
+//	GET  /jobs/{id}/logs       Log (text/plain, ?offset=<bytes>)
+//	GET  /jobs/{id}/reports    nur die Reports
+//	POST /jobs/{id}/cancel     Job abbrechen
+//
+// Ist token gesetzt, wird "Authorization: Bearer <token>" verlangt.
+func (s *Service) Handler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", s.handleSubmit)
+	mux.HandleFunc("GET /jobs", s.handleList)
+	mux.HandleFunc("GET /jobs/{id}", s.handleGet)
+	mux.HandleFunc("GET /jobs/{id}/logs", s.handleLogs)
+	mux.HandleFunc("GET /jobs/{id}/reports", s.handleReports)
+	mux.HandleFunc("POST /jobs/{id}/cancel", s.handleCancel)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok\n")
+	})
+
+	if token == "" {
+		return mux
+	}
+	return RequireToken(token, mux)
+}
+
+// RequireToken schützt h mit einem statischen Bearer-Token (ausser /healthz).
+func RequireToken(token string, h http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (s *Service) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	req.Source = "api"
+	j, err := s.Submit(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, j)
+}
+
+func (s *Service) handleList(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.Store.List())
+}
+
+func (s *Service) handleGet(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.Store.Get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	writeJSON(w, http.StatusOK, jobView{Job: j, Reports: readReports(j)})
+}
+
+func (s *Service) handleReports(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.Store.Get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	writeJSON(w, http.StatusOK, readReports(j))
+}
+
+func (s *Service) handleLogs(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.Store.Get(id); !ok {
+		writeError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+
+	f, err := os.Open(s.Store.LogPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			return // noch nicht gestartet
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer f.Close()
+
+	if off, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64); err == nil && off > 0 {
+		if _, err := f.Seek(off, io.SeekStart); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.Copy(w, f)
+}
+
+func (s *Service) handleCancel(w http.ResponseWriter, r *http.Request) {
+	j, err := s.Cancel(r.PathValue("id"))
+	switch {
+	case errors.Is(err, ErrJobDone):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusNotFound, err)
+	default:
+		writeJSON(w, http.StatusAccepted, j)
+	}
+}
+
+func readReports(j Job) []report.BottleReport {
+	var out []report.BottleReport
+	for _, res := range j.Results {
+		if res.ReportFile == "" {
+			continue
+		}
+		b, err := os.ReadFile(res.ReportFile)
+		if err != nil {
+			continue
+		}
+		var rep report.BottleReport
+		if json.Unmarshal(b, &rep) == nil {
+			out = append(out, rep)
+		}
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
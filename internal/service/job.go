@@ -0,0 +1,92 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gov-brew-bottle-creation/internal/formula"
+)
+
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+// Steps, die ein Job anfordern kann (werden vom Runner auf CLI-Flags abgebildet).
+var KnownSteps = map[string]bool{
+	"dry-run":        true,
+	"build":          true,
+	"upload":         true,
+	"update-formula": true,
+	"sign":           true,
+	"sbom":           true,
+	"provenance":     true,
+	"oci-push":       true,
+	"force-build":    true,
+}
+
+// JobRequest ist der Body von POST /jobs.
+type JobRequest struct {
+	Refs       []string `json:"refs"`
+	Tag        string   `json:"tag,omitempty"`
+	Steps      []string `json:"steps,omitempty"`
+	BrewPrefix string   `json:"brew_prefix,omitempty"`
+	Source     string   `json:"source,omitempty"` // z.B. "api" oder "webhook"
+}
+
+func (r JobRequest) Validate() error {
+	if len(r.Refs) == 0 {
+		return fmt.Errorf("refs must not be empty")
+	}
+	// Refs landen als --ref im Subprozess und in der brew argv
+	for _, ref := range r.Refs {
+		if _, _, err := formula.ParseRef(ref); err != nil {
+			return err
+		}
+	}
+	for _, s := range r.Steps {
+		if !KnownSteps[s] {
+			return fmt.Errorf("unknown step %q", s)
+		}
+	}
+	return nil
+}
+
+// RefResult: Ergebnis eines Refs innerhalb eines Jobs.
+type RefResult struct {
+	Ref        string `json:"ref"`
+	ExitCode   int    `json:"exit_code"`
+	Error      string `json:"error,omitempty"`
+	ReportFile string `json:"report_file,omitempty"`
+}
+
+type Job struct {
+	ID string `json:"id"`
+	JobRequest
+
+	Status   JobStatus   `json:"status"`
+	Error    string      `json:"error,omitempty"`
+	Created  time.Time   `json:"created"`
+	Started  *time.Time  `json:"started,omitempty"`
+	Finished *time.Time  `json:"finished,omitempty"`
+	Results  []RefResult `json:"results,omitempty"`
+
+	CancelRequested bool `json:"cancel_requested,omitempty"`
+}
+
+func (j *Job) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+func newJobID(now time.Time) string {
+	var b [3]byte
+	_, _ = rand.Read(b[:])
+	return now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Runner führt die Pipeline für genau einen Ref aus (plan/build/upload) und schreibt
+// Ausgaben nach log und den finalen Report nach reportPath. Rückgabe ist der Exit-Code.
+type Runner func(ctx context.Context, job Job, ref string, log io.Writer, reportPath string) (int, error)
+
+// Service arbeitet die Jobs ab: pro Brew-Prefix immer nur ein Job gleichzeitig.
+type Service struct {
+	Store         *Store
+	Run           Runner
+	DefaultPrefix string
+	Prefixes      []string // weitere erlaubte brew_prefix Werte (SERVE_BREW_PREFIXES)
+	DefaultTag    string
+
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	workers map[string]chan struct{} // prefix -> wake-up
+	cancels map[string]context.CancelFunc
+}
+
+// Start startet die Worker für alle Prefixe mit wartenden Jobs.
+func (s *Service) Start(ctx context.Context) {
+	s.ctx, s.stop = context.WithCancel(ctx)
+	s.workers = map[string]chan struct{}{}
+	s.cancels = map[string]context.CancelFunc{}
+	for _, p := range s.Store.QueuedPrefixes() {
+		s.wake(p)
+	}
+}
+
+// Stop bricht laufende Jobs ab; sie bleiben "running" im Store und werden beim nächsten Start erneut eingereiht.
+func (s *Service) Stop() {
+	s.stop()
+	s.wg.Wait()
+}
+
+// Submit legt einen Job an und weckt den Worker seines Prefix.
+func (s *Service) Submit(req JobRequest) (Job, error) {
+	if req.Tag == "" {
+		req.Tag = s.DefaultTag
+	}
+	if req.BrewPrefix == "" {
+		req.BrewPrefix = s.DefaultPrefix
+	}
+	if req.Source == "" {
+		req.Source = "api"
+	}
+	if err := req.Validate(); err != nil {
+		return Job{}, err
+	}
+	// brew_prefix wird zu HOMEBREW_PREFIX/BREW_BIN des Subprozesses: nur konfigurierte Werte
+	if req.BrewPrefix != s.DefaultPrefix && !slices.Contains(s.Prefixes, req.BrewPrefix) {
+		return Job{}, fmt.Errorf("brew_prefix %q not allowed (see SERVE_BREW_PREFIXES)", req.BrewPrefix)
+	}
+
+	now := time.Now()
+	j := &Job{ID: newJobID(now), JobRequest: req, Status: JobQueued, Created: now}
+	if err := s.Store.Add(j); err != nil {
+		return Job{}, err
+	}
+	// Kopie vor dem Wecken: ab dann ändert der Worker j über den Store
+	out := *j
+	s.wake(req.BrewPrefix)
+	return out, nil
+}
+
+// ErrJobDone: Job ist bereits beendet und kann nicht mehr abgebrochen werden.
+var ErrJobDone = errors.New("job already finished")
+
+// Cancel bricht einen wartenden oder laufenden Job ab.
+func (s *Service) Cancel(id string) (Job, error) {
+	var wasDone bool
+	j, err := s.Store.Update(id, func(j *Job) {
+		if j.Done() {
+			wasDone = true
+			return
+		}
+		j.CancelRequested = true
+		if j.Status == JobQueued {
+			now := time.Now()
+			j.Status = JobCanceled
+			j.Finished = &now
+		}
+	})
+	if err != nil {
+		return Job{}, err
+	}
+	if wasDone {
+		return j, ErrJobDone
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.cancels[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+	return j, nil
+}
+
+func (s *Service) wake(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.workers[prefix]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		s.workers[prefix] = ch
+		s.wg.Add(1)
+		go s.worker(prefix, ch)
+	}
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) worker(prefix string, wake <-chan struct{}) {
+	defer s.wg.Done()
+	for {
+		for {
+			if s.ctx.Err() != nil {
+				return
+			}
+			j, ok := s.Store.NextQueued(prefix)
+			if !ok {
+				break
+			}
+			s.runJob(j)
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-wake:
+		}
+	}
+}
+
+func (s *Service) runJob(j Job) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	s.cancels[j.ID] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.cancels, j.ID)
+		s.mu.Unlock()
+	}()
+
+	now := time.Now()
+	started := false
+	j, err := s.Store.Update(j.ID, func(j *Job) {
+		if j.Status != JobQueued {
+			return // inzwischen abgebrochen
+		}
+		started = true
+		j.Status = JobRunning
+		j.Started = &now
+		j.Results = nil
+	})
+	if err != nil || !started {
+		return
+	}
+
+	logf, err := os.OpenFile(s.Store.LogPath(j.ID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		s.finish(j.ID, JobFailed, fmt.Sprintf("open log: %v", err))
+		return
+	}
+	defer logf.Close()
+
+	failed := false
+	for i, ref := range j.Refs {
+		if ctx.Err() != nil {
+			break
+		}
+		_, _ = fmt.Fprintf(logf, "==> [%s] %s\n", time.Now().Format(time.RFC3339), ref)
+
+		reportPath := filepath.Join(s.Store.JobDir(j.ID), "reports", fmt.Sprintf("%02d.json", i))
+		code, rerr := s.Run(ctx, j, ref, logf, reportPath)
+
+		res := RefResult{Ref: ref, ExitCode: code}
+		if _, err := os.Stat(reportPath); err == nil {
+			res.ReportFile = reportPath
+		}
+		if rerr != nil {
+			res.Error = rerr.Error()
+		}
+		if code != 0 || rerr != nil {
+			failed = true
+		}
+		_, _ = s.Store.Update(j.ID, func(j *Job) { j.Results = append(j.Results, res) })
+	}
+
+	cur, _ := s.Store.Get(j.ID)
+	switch {
+	case cur.CancelRequested:
+		s.finish(j.ID, JobCanceled, "canceled")
+	case s.ctx.Err() != nil:
+		// Service wird gestoppt: Job bleibt "running" und wird beim Neustart wieder eingereiht
+		_, _ = fmt.Fprintln(logf, "==> service stopped, job will be requeued")
+	case failed:
+		s.finish(j.ID, JobFailed, "one or more refs failed")
+	default:
+		s.finish(j.ID, JobSucceeded, "")
+	}
+}
+
+func (s *Service) finish(id string, st JobStatus, msg string) {
+	now := time.Now()
+	_, _ = s.Store.Update(id, func(j *Job) {
+		j.Status = st
+		j.Error = msg
+		j.Finished = &now
+	})
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store hält die Jobs im Speicher und persistiert jeden Job als <dir>/jobs/<id>/job.json.
+type Store struct {
+	dir string
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// OpenStore lädt alle Jobs aus dir. Jobs, die beim letzten Stop liefen, werden wieder eingereiht.
+func OpenStore(dir string) (*Store, error) {
+	s := &Store{dir: dir, jobs: map[string]*Job{}}
+	if err := os.MkdirAll(filepath.Join(dir, "jobs"), 0o755); err != nil {
+		return nil, fmt.Errorf("create state dir: %w", err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "jobs", "*", "job.json"))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+	for _, p := range matches {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read job: %w", err)
+		}
+		var j Job
+		if err := json.Unmarshal(b, &j); err != nil {
+			return nil, fmt.Errorf("parse job %s: %w", p, err)
+		}
+		if j.Status == JobRunning {
+			j.Status = JobQueued
+			j.Started = nil
+			j.Results = nil
+			if err := s.write(&j); err != nil {
+				return nil, err
+			}
+		}
+		s.jobs[j.ID] = &j
+	}
+	return s, nil
+}
+
+func (s *Store) JobDir(id string) string {
+	return filepath.Join(s.dir, "jobs", id)
+}
+
+func (s *Store) LogPath(id string) string {
+	return filepath.Join(s.JobDir(id), "log.txt")
+}
+
+// Add legt einen neuen Job an (Status queued).
+func (s *Store) Add(j *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Join(s.JobDir(j.ID), "reports"), 0o755); err != nil {
+		return fmt.Errorf("create job dir: %w", err)
+	}
+	if err := s.write(j); err != nil {
+		return err
+	}
+	s.jobs[j.ID] = j
+	return nil
+}
+
+// Get liefert eine Kopie des Jobs.
+func (s *Store) Get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *j, true
+}
+
+// List liefert Kopien aller Jobs, neueste zuerst.
+func (s *Store) List() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		out = append(out, *j)
+	}
+	sort.Slice(out, func(i, k int) bool { return out[i].Created.After(out[k].Created) })
+	return out
+}
+
+// Update ändert einen Job unter Lock und persistiert ihn.
+func (s *Store) Update(id string, fn func(j *Job)) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("job %s not found", id)
+	}
+	fn(j)
+	if err := s.write(j); err != nil {
+		return Job{}, err
+	}
+	return *j, nil
+}
+
+// NextQueued liefert den ältesten wartenden Job für prefix.
+func (s *Store) NextQueued(prefix string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next *Job
+	for _, j := range s.jobs {
+		if j.Status != JobQueued || j.BrewPrefix != prefix {
+			continue
+		}
+		if next == nil || j.Created.Before(next.Created) {
+			next = j
+		}
+	}
+	if next == nil {
+		return Job{}, false
+	}
+	return *next, true
+}
+
+// Prefixes mit wartenden Jobs (für den Start nach einem Restart).
+func (s *Store) QueuedPrefixes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := map[string]bool{}
+	var out []string
+	for _, j := range s.jobs {
+		if j.Status == JobQueued && !seen[j.BrewPrefix] {
+			seen[j.BrewPrefix] = true
+			out = append(out, j.BrewPrefix)
+		}
+	}
+	return out
+}
+
+// write: atomar über <file>.tmp + rename (Aufrufer hält s.mu oder besitzt j exklusiv)
+func (s *Store) write(j *Job) error {
+	b, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal job: %w", err)
+	}
+	p := filepath.Join(s.JobDir(j.ID), "job.json")
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("create job dir: %w", err)
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write job: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("rename job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenStoreRequeuesRunning(t *testing.T) {
+	started := time.Now().Add(-time.Minute)
+	finished := time.Now()
+
+	tests := []struct {
+		status      JobStatus
+		want        JobStatus
+		keepResults bool
+	}{
+		{JobRunning, JobQueued, false},
+		{JobQueued, JobQueued, true},
+		{JobSucceeded, JobSucceeded, true},
+		{JobFailed, JobFailed, true},
+		{JobCanceled, JobCanceled, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			dir := t.TempDir()
+			s, err := OpenStore(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			j := &Job{
+				ID:         "job-1",
+				JobRequest: JobRequest{Refs: []string{"tlchmi/ch-gov-brew/gov-srt"}, BrewPrefix: "/opt/homebrew"},
+				Status:     tt.status,
+				Created:    started,
+				Started:    &started,
+				Results:    []RefResult{{Ref: "tlchmi/ch-gov-brew/gov-srt", ExitCode: 1}},
+			}
+			if tt.status != JobRunning && tt.status != JobQueued {
+				j.Finished = &finished
+			}
+			if err := s.Add(j); err != nil {
+				t.Fatal(err)
+			}
+
+			// zweimal öffnen: der Requeue muss auf Platte landen, nicht nur im Speicher
+			for i := 0; i < 2; i++ {
+				s, err = OpenStore(dir)
+				if err != nil {
+					t.Fatalf("OpenStore: %v", err)
+				}
+				got, ok := s.Get("job-1")
+				if !ok {
+					t.Fatalf("job lost after reopen")
+				}
+				if got.Status != tt.want {
+					t.Fatalf("status = %s, want %s", got.Status, tt.want)
+				}
+				if hasResults := len(got.Results) > 0; hasResults != tt.keepResults {
+					t.Fatalf("results = %v, keep = %v", got.Results, tt.keepResults)
+				}
+				if !tt.keepResults && got.Started != nil {
+					t.Fatalf("started not reset for requeued job")
+				}
+			}
+
+			prefixes := s.QueuedPrefixes()
+			if wantQueued := tt.want == JobQueued; (len(prefixes) == 1) != wantQueued {
+				t.Fatalf("queued prefixes = %v", prefixes)
+			}
+		})
+	}
+}
+
+func TestNextQueuedOldestPerPrefix(t *testing.T) {
+	s, err := OpenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Now()
+	for i, j := range []Job{
+		{ID: "b", JobRequest: JobRequest{BrewPrefix: "/opt/homebrew"}, Status: JobQueued, Created: base.Add(2 * time.Second)},
+		{ID: "a", JobRequest: JobRequest{BrewPrefix: "/opt/homebrew"}, Status: JobQueued, Created: base.Add(time.Second)},
+		{ID: "old", JobRequest: JobRequest{BrewPrefix: "/opt/homebrew"}, Status: JobSucceeded, Created: base},
+		{ID: "other", JobRequest: JobRequest{BrewPrefix: "/usr/local"}, Status: JobQueued, Created: base},
+	} {
+		j := j
+		if err := s.Add(&j); err != nil {
+			t.Fatalf("add %d: %v", i, err)
+		}
+	}
+	if j, ok := s.NextQueued("/opt/homebrew"); !ok || j.ID != "a" {
+		t.Fatalf("NextQueued = %v %v, want a", j.ID, ok)
+	}
+	if j, ok := s.NextQueued("/usr/local"); !ok || j.ID != "other" {
+		t.Fatalf("NextQueued = %v %v, want other", j.ID, ok)
+	}
+	if _, ok := s.NextQueued("/nowhere"); ok {
+		t.Fatalf("NextQueued for unknown prefix")
+	}
+}
+
+// Stop während ein Job läuft: nach Neustart wird er erneut ausgeführt.
+func TestServiceRequeuesAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	running := make(chan struct{})
+	blocking := func(ctx context.Context, _ Job, _ string, _ io.Writer, _ string) (int, error) {
+		close(running)
+		<-ctx.Done()
+		return 130, ctx.Err()
+	}
+	svc := &Service{Store: store, Run: blocking, DefaultPrefix: "/opt/homebrew", DefaultTag: "arm64_sonoma"}
+	svc.Start(context.Background())
+
+	j, err := svc.Submit(JobRequest{Refs: []string{"tlchmi/ch-gov-brew/gov-srt"}})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	select {
+	case <-running:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not start")
+	}
+	svc.Stop()
+
+	if got, _ := store.Get(j.ID); got.Status != JobRunning {
+		t.Fatalf("status after stop = %s, want running", got.Status)
+	}
+
+	// Neustart
+	store, err = OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Get(j.ID); got.Status != JobQueued {
+		t.Fatalf("status after reopen = %s, want queued", got.Status)
+	}
+
+	var ran []string
+	done := make(chan struct{})
+	ok := func(_ context.Context, job Job, ref string, _ io.Writer, _ string) (int, error) {
+		ran = append(ran, job.ID+" "+ref)
+		close(done)
+		return 0, nil
+	}
+	svc = &Service{Store: store, Run: ok, DefaultPrefix: "/opt/homebrew", DefaultTag: "arm64_sonoma"}
+	svc.Start(context.Background())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("requeued job did not run")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := store.Get(j.ID)
+		if got.Status == JobSucceeded {
+			if len(got.Results) != 1 || got.Results[0].ExitCode != 0 {
+				t.Fatalf("results = %+v", got.Results)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %s, want succeeded", got.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	svc.Stop()
+
+	if len(ran) != 1 || ran[0] != j.ID+" tlchmi/ch-gov-brew/gov-srt" {
+		t.Fatalf("ran = %v", ran)
+	}
+}
+
+func TestSubmitRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     JobRequest
+		wantErr string
+	}{
+		{"no refs", JobRequest{}, "refs must not be empty"},
+		{"option as ref", JobRequest{Refs: []string{"--help"}}, "ref"},
+		{"bad ref part", JobRequest{Refs: []string{"tlchmi/ch-gov-brew/gov srt"}}, "ref"},
+		{"unknown step", JobRequest{Refs: []string{"tlchmi/ch-gov-brew/gov-srt"}, Steps: []string{"rm"}}, "unknown step"},
+		{"prefix not allowed", JobRequest{Refs: []string{"tlchmi/ch-gov-brew/gov-srt"}, BrewPrefix: "/tmp/evil"}, "not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := OpenStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			svc := &Service{Store: store, DefaultPrefix: "/opt/homebrew", Prefixes: []string{"/usr/local"}}
+			_, err = svc.Submit(tt.req)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Submit error = %v, want %q", err, tt.wantErr)
+			}
+			if n := len(store.List()); n != 0 {
+				t.Fatalf("%d job(s) stored for a rejected request", n)
+			}
+		})
+	}
+}
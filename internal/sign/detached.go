@@ -0,0 +1,107 @@
+package sign
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gov-brew-bottle-creation/internal/hash"
+)
+
+// Suffix der Signaturdatei neben dem signierten Artefakt.
+const Suffix = ".sig"
+
+// Signature ist der Inhalt einer <file>.sig Datei.
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	File      string `json:"file"`
+	Sha256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+// SignFile erzeugt <filePath>.sig und liefert den Pfad der Signaturdatei.
+func SignFile(ctx context.Context, s Signer, filePath string) (string, error) {
+	sum, err := hash.FileSHA256(filePath)
+	if err != nil {
+		return "", fmt.Errorf("sha256: %w", err)
+	}
+	sig := Signature{
+		Algorithm: s.Algorithm(),
+		KeyID:     s.KeyID(),
+		File:      filepath.Base(filePath),
+		Sha256:    sum,
+	}
+	digest := sha256.Sum256(sig.statement())
+	raw, err := s.Sign(ctx, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign %s: %w", sig.File, err)
+	}
+	sig.Signature = base64.StdEncoding.EncodeToString(raw)
+	b, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal signature: %w", err)
+	}
+
+	sigPath := filePath + Suffix
+	if err := os.WriteFile(sigPath, append(b, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("write signature: %w", err)
+	}
+	return sigPath, nil
+}
+
+// VerifyFile prüft filePath gegen die Signatur in sigPath und liefert die Key-ID des Signierers.
+func VerifyFile(keys KeySet, filePath, sigPath string) (string, error) {
+	b, err := os.ReadFile(sigPath)
+	if err != nil {
+		return "", fmt.Errorf("read signature: %w", err)
+	}
+	var sig Signature
+	if err := json.Unmarshal(b, &sig); err != nil {
+		return "", fmt.Errorf("parse signature %s: %w", sigPath, err)
+	}
+	if sig.Algorithm != AlgorithmEd25519 {
+		return "", fmt.Errorf("unsupported signature algorithm %q", sig.Algorithm)
+	}
+
+	// die Signatur gilt für genau diese Datei: ein umbenanntes Bottle (anderer Tag/Version) fällt durch
+	if name := filepath.Base(filePath); sig.File != name {
+		return "", fmt.Errorf("signature %s is for %q, not %q", filepath.Base(sigPath), sig.File, name)
+	}
+
+	pub, ok := keys[sig.KeyID]
+	if !ok {
+		return "", fmt.Errorf("key %s is not trusted", sig.KeyID)
+	}
+
+	sum, err := hash.FileSHA256(filePath)
+	if err != nil {
+		return "", fmt.Errorf("sha256: %w", err)
+	}
+	if sum != sig.Sha256 {
+		return "", fmt.Errorf("sha256 mismatch: file=%s signature=%s", sum, sig.Sha256)
+	}
+	raw, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return "", fmt.Errorf("decode signature: %w", err)
+	}
+	// Statement aus dem, was tatsächlich geprüft wurde (Dateiname, berechneter Hash), nicht aus der .sig
+	signed := Signature{Algorithm: sig.Algorithm, KeyID: sig.KeyID, File: filepath.Base(filePath), Sha256: sum}
+	digest := sha256.Sum256(signed.statement())
+	if !ed25519.Verify(pub, message(digest[:]), raw) {
+		return "", fmt.Errorf("invalid signature for %s (key %s)", filepath.Base(filePath), sig.KeyID)
+	}
+	return sig.KeyID, nil
+}
+
+// statement: kanonischer Text über alle Felder ausser der Signatur selbst. Signiert wird dessen
+// SHA-256, so sind auch Algorithmus, Key-ID und Dateiname geschützt (nicht nur der Inhalt).
+func (s Signature) statement() []byte {
+	return fmt.Appendf(nil, "gov-bottle-signature-v2\nalgorithm %q\nkey_id %q\nfile %q\nsha256 %q\n",
+		s.Algorithm, s.KeyID, s.File, s.Sha256)
+}
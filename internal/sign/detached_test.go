@@ -0,0 +1,174 @@
+package sign
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gov-brew-bottle-creation/internal/hash"
+)
+
+func newTestSigner(t *testing.T) *FileSigner {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &FileSigner{key: key, keyID: KeyIDFor(key.Public().(ed25519.PublicKey))}
+}
+
+func TestVerifyFile(t *testing.T) {
+	signer := newTestSigner(t)
+	other := newTestSigner(t)
+	trusted := KeySet{signer.KeyID(): signer.PublicKey()}
+
+	tests := []struct {
+		name    string
+		keys    KeySet
+		mutate  func(t *testing.T, file, sig string) (string, string) // liefert zu prüfende Datei und Signatur
+		wantErr string
+	}{
+		{
+			name: "valid",
+			keys: trusted,
+		},
+		{
+			name:    "untrusted key",
+			keys:    KeySet{other.KeyID(): other.PublicKey()},
+			wantErr: "is not trusted",
+		},
+		{
+			name: "file modified",
+			keys: trusted,
+			mutate: func(t *testing.T, file, sig string) (string, string) {
+				writeFile(t, file, "tampered")
+				return file, sig
+			},
+			wantErr: "sha256 mismatch",
+		},
+		{
+			name: "renamed file",
+			keys: trusted,
+			mutate: func(t *testing.T, file, sig string) (string, string) {
+				renamed := filepath.Join(filepath.Dir(file), "gov-srt--1.5.4.x86_64_linux.bottle.tar.gz")
+				if err := os.Rename(file, renamed); err != nil {
+					t.Fatal(err)
+				}
+				return renamed, sig
+			},
+			wantErr: "not \"gov-srt--1.5.4.x86_64_linux.bottle.tar.gz\"",
+		},
+		{
+			name: "renamed file with edited file field",
+			keys: trusted,
+			mutate: func(t *testing.T, file, sig string) (string, string) {
+				renamed := filepath.Join(filepath.Dir(file), "gov-srt--1.5.4.x86_64_linux.bottle.tar.gz")
+				if err := os.Rename(file, renamed); err != nil {
+					t.Fatal(err)
+				}
+				editSig(t, sig, func(s *Signature) { s.File = filepath.Base(renamed) })
+				return renamed, sig
+			},
+			wantErr: "invalid signature",
+		},
+		{
+			name: "key id swapped to another trusted key",
+			keys: KeySet{signer.KeyID(): signer.PublicKey(), other.KeyID(): signer.PublicKey()},
+			mutate: func(t *testing.T, file, sig string) (string, string) {
+				editSig(t, sig, func(s *Signature) { s.KeyID = other.KeyID() })
+				return file, sig
+			},
+			wantErr: "invalid signature",
+		},
+		{
+			name: "signature bytes changed",
+			keys: trusted,
+			mutate: func(t *testing.T, file, sig string) (string, string) {
+				editSig(t, sig, func(s *Signature) {
+					s.Signature = strings.Repeat("A", len(s.Signature)-2) + "=="
+				})
+				return file, sig
+			},
+			wantErr: "invalid signature",
+		},
+		{
+			name: "sha256 field and file swapped together",
+			keys: trusted,
+			mutate: func(t *testing.T, file, sig string) (string, string) {
+				writeFile(t, file, "tampered")
+				sum, err := hash.FileSHA256(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				editSig(t, sig, func(s *Signature) { s.Sha256 = sum })
+				return file, sig
+			},
+			wantErr: "invalid signature",
+		},
+		{
+			name: "unknown algorithm",
+			keys: trusted,
+			mutate: func(t *testing.T, file, sig string) (string, string) {
+				editSig(t, sig, func(s *Signature) { s.Algorithm = "rsa" })
+				return file, sig
+			},
+			wantErr: "unsupported signature algorithm",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "gov-srt--1.5.4.arm64_sonoma.bottle.tar.gz")
+			writeFile(t, file, "bottle")
+			sig, err := SignFile(context.Background(), signer, file)
+			if err != nil {
+				t.Fatalf("SignFile: %v", err)
+			}
+			if tt.mutate != nil {
+				file, sig = tt.mutate(t, file, sig)
+			}
+
+			keyID, err := VerifyFile(tt.keys, file, sig)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifyFile: %v", err)
+				}
+				if keyID != signer.KeyID() {
+					t.Fatalf("key id = %s, want %s", keyID, signer.KeyID())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("VerifyFile error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, p, content string) {
+	t.Helper()
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func editSig(t *testing.T, p string, fn func(*Signature)) {
+	t.Helper()
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Signature
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+	fn(&s)
+	if b, err = json.Marshal(s); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, p, string(b))
+}
@@ -0,0 +1,121 @@
+package sign
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const AlgorithmEd25519 = "ed25519"
+
+// Signer signiert einen SHA-256 Digest (den des Statements einer .sig). Implementierungen können den Key lokal
+// halten (FileSigner) oder an ein HSM/KMS delegieren.
+type Signer interface {
+	KeyID() string
+	Algorithm() string
+	Sign(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// Provider erzeugt einen Signer aus dem Teil nach "<scheme>:".
+type Provider func(ctx context.Context, location string) (Signer, error)
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{
+		"file": func(_ context.Context, location string) (Signer, error) { return LoadFileSigner(location) },
+	}
+)
+
+// Register macht einen weiteren Key-Provider verfügbar (z.B. "pkcs11", "kms").
+func Register(scheme string, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[scheme] = p
+}
+
+// Load lädt einen Signer aus "<scheme>:<location>". Ohne Schema wird "file" angenommen.
+func Load(ctx context.Context, spec string) (Signer, error) {
+	scheme, location, ok := strings.Cut(spec, ":")
+	if !ok || strings.ContainsAny(scheme, `/\.`) {
+		scheme, location = "file", spec
+	}
+
+	mu.RLock()
+	p, found := providers[scheme]
+	mu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown signer provider %q", scheme)
+	}
+	return p(ctx, location)
+}
+
+// FileSigner hält einen ed25519 Private Key aus einer Datei.
+type FileSigner struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// LoadFileSigner liest einen PKCS#8 PEM Key ("openssl genpkey -algorithm ed25519")
+// oder einen base64 kodierten 32-Byte Seed.
+func LoadFileSigner(path string) (*FileSigner, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+
+	var key ed25519.PrivateKey
+	if blk, _ := pem.Decode(b); blk != nil {
+		k, err := x509.ParsePKCS8PrivateKey(blk.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse signing key: %w", err)
+		}
+		edk, ok := k.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key is %T, expected ed25519", k)
+		}
+		key = edk
+	} else {
+		seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+		if err != nil {
+			return nil, fmt.Errorf("decode signing key: %w", err)
+		}
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("signing key seed has %d bytes, expected %d", len(seed), ed25519.SeedSize)
+		}
+		key = ed25519.NewKeyFromSeed(seed)
+	}
+
+	return &FileSigner{key: key, keyID: KeyIDFor(key.Public().(ed25519.PublicKey))}, nil
+}
+
+func (s *FileSigner) KeyID() string     { return s.keyID }
+func (s *FileSigner) Algorithm() string { return AlgorithmEd25519 }
+
+func (s *FileSigner) Sign(_ context.Context, digest []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, message(digest)), nil
+}
+
+// PublicKey für die trusted-keys Datei.
+func (s *FileSigner) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// KeyIDFor: die ersten 16 Hex-Zeichen des SHA-256 über den Public Key.
+func KeyIDFor(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// message: Domain-Separation, damit die Signatur nicht für andere Zwecke taugt.
+// v2: digest ist der SHA-256 des Statements (siehe statement), nicht mehr der Datei allein.
+func message(digest []byte) []byte {
+	return append([]byte("gov-bottle-signature-v2\n"), digest...)
+}
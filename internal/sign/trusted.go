@@ -0,0 +1,84 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeySet: Key-ID -> Public Key
+type KeySet map[string]ed25519.PublicKey
+
+// LoadTrustedKeys liest eine trusted-keys Datei. Erlaubt sind entweder
+//   - PEM "PUBLIC KEY" Blöcke (openssl pkey -pubout), die Key-ID wird abgeleitet, oder
+//   - Zeilen "<key-id> <base64 public key>" bzw. nur "<base64 public key>"
+//     (eigene Key-IDs z.B. für HSM/KMS Keys; "#" Kommentare erlaubt).
+func LoadTrustedKeys(path string) (KeySet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read trusted keys: %w", err)
+	}
+
+	var keys KeySet
+	if bytes.Contains(b, []byte("-----BEGIN")) {
+		keys, err = parsePEMKeys(b)
+	} else {
+		keys, err = parseKeyLines(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no trusted keys in %s", path)
+	}
+	return keys, nil
+}
+
+func parsePEMKeys(b []byte) (KeySet, error) {
+	keys := KeySet{}
+	for {
+		blk, rest := pem.Decode(b)
+		if blk == nil {
+			return keys, nil
+		}
+		b = rest
+
+		k, err := x509.ParsePKIXPublicKey(blk.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %w", err)
+		}
+		pub, ok := k.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is %T, expected ed25519", k)
+		}
+		keys[KeyIDFor(pub)] = pub
+	}
+}
+
+func parseKeyLines(b []byte) (KeySet, error) {
+	keys := KeySet{}
+	for i, ln := range strings.Split(string(b), "\n") {
+		ln = strings.TrimSpace(ln)
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		fields := strings.Fields(ln)
+		raw, err := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("line %d: invalid ed25519 public key", i+1)
+		}
+		pub := ed25519.PublicKey(raw)
+
+		id := KeyIDFor(pub)
+		if len(fields) > 1 {
+			id = fields[0]
+		}
+		keys[id] = pub
+	}
+	return keys, nil
+}
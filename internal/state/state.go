@@ -0,0 +1,197 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gov-brew-bottle-creation/internal/hash"
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// Schritte im Checkpoint
+const (
+	StepBuild   = "build"
+	StepOCIPush = "oci-push"
+	StepUpload  = "upload"
+)
+
+// Artifact: Ausgabe eines Schritts; gültig solange die Datei die gleiche sha256 hat.
+type Artifact struct {
+	Path   string `json:"path"`
+	Sha256 string `json:"sha256"`
+	URL    string `json:"url,omitempty"` // Upload-Ziel
+}
+
+type Step struct {
+	Started   time.Time  `json:"started"`
+	Finished  time.Time  `json:"finished,omitzero"`
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+}
+
+// State: Checkpoint eines Refs im Workdir (für --resume)
+type State struct {
+	Ref     string           `json:"ref"`
+	Version string           `json:"version"`
+	Tag     string           `json:"tag"`
+	Steps   map[string]*Step `json:"steps"`
+
+	// letzter geschriebener Report (Felder wie oci_*, cache_key beim Resume übernehmen)
+	Report *report.BottleReport `json:"report,omitempty"`
+
+	path string
+}
+
+func New(path string) *State {
+	return &State{path: path, Steps: map[string]*Step{}}
+}
+
+// Load liest den Checkpoint; fehlt die Datei, gibt es einen leeren State.
+func Load(path string) (*State, error) {
+	st := New(path)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return st, nil
+		}
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, fmt.Errorf("parse state %s: %w", path, err)
+	}
+	if st.Steps == nil {
+		st.Steps = map[string]*Step{}
+	}
+	return st, nil
+}
+
+// Matches: gehört der Checkpoint zu diesem Ref/Version/Tag?
+func (s *State) Matches(ref, version, tag string) bool {
+	return s.Ref == ref && s.Version == version && s.Tag == tag
+}
+
+// Reset verwirft alle Schritte (neuer Run ohne --resume).
+func (s *State) Reset(ref, version, tag string) {
+	s.Ref, s.Version, s.Tag = ref, version, tag
+	s.Steps = map[string]*Step{}
+	s.Report = nil
+}
+
+// Done: Schritt abgeschlossen und Artefakte noch vorhanden und unverändert?
+// Alle Schritte erzeugen Artefakte; ohne (altes oder von Hand gekürztes state.json) gilt er als offen.
+func (s *State) Done(name string) (*Step, bool) {
+	st := s.Steps[name]
+	if st == nil || st.Finished.IsZero() || len(st.Artifacts) == 0 {
+		return nil, false
+	}
+	for _, a := range st.Artifacts {
+		if !valid(a) {
+			return nil, false
+		}
+	}
+	return st, true
+}
+
+// Complete markiert einen Schritt als fertig; sha256 der Artefakte wird hier berechnet.
+func (s *State) Complete(name string, started, finished time.Time, arts ...Artifact) error {
+	st := &Step{Started: started, Finished: finished}
+	for _, a := range arts {
+		a, err := withSum(a)
+		if err != nil {
+			return err
+		}
+		st.Artifacts = append(st.Artifacts, a)
+	}
+	s.Steps[name] = st
+	return s.Save()
+}
+
+// Add hängt ein Artefakt an einen laufenden Schritt an (z.B. pro hochgeladener Datei).
+func (s *State) Add(name string, a Artifact) error {
+	a, err := withSum(a)
+	if err != nil {
+		return err
+	}
+	st := s.Steps[name]
+	if st == nil {
+		st = &Step{Started: time.Now().UTC()}
+		s.Steps[name] = st
+	}
+	for i, old := range st.Artifacts {
+		if old.Path == a.Path && old.URL == a.URL {
+			st.Artifacts[i] = a
+			return s.Save()
+		}
+	}
+	st.Artifacts = append(st.Artifacts, a)
+	return s.Save()
+}
+
+// Finish schliesst einen mit Add aufgebauten Schritt ab.
+func (s *State) Finish(name string) error {
+	st := s.Steps[name]
+	if st == nil {
+		st = &Step{Started: time.Now().UTC()}
+		s.Steps[name] = st
+	}
+	st.Finished = time.Now().UTC()
+	return s.Save()
+}
+
+// Has: wurde genau diese Datei (gleicher Inhalt) schon an url übertragen?
+func (s *State) Has(name, path, url string) bool {
+	st := s.Steps[name]
+	if st == nil {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, a := range st.Artifacts {
+		if a.Path == abs && a.URL == url {
+			return valid(a)
+		}
+	}
+	return false
+}
+
+// Save schreibt den Checkpoint atomar.
+func (s *State) Save() error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	return nil
+}
+
+func (s *State) Path() string { return s.path }
+
+func withSum(a Artifact) (Artifact, error) {
+	abs, err := filepath.Abs(a.Path)
+	if err != nil {
+		return a, err
+	}
+	a.Path = abs
+	if a.Sha256 == "" {
+		if a.Sha256, err = hash.FileSHA256(abs); err != nil {
+			return a, fmt.Errorf("state: %w", err)
+		}
+	}
+	return a, nil
+}
+
+func valid(a Artifact) bool {
+	sum, err := hash.FileSHA256(a.Path)
+	return err == nil && sum == a.Sha256
+}
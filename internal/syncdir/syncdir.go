@@ -0,0 +1,244 @@
+package syncdir
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gov-brew-bottle-creation/internal/hash"
+	"gov-brew-bottle-creation/internal/naming"
+	"gov-brew-bottle-creation/internal/nexus"
+)
+
+type Action string
+
+const (
+	ActionUpload     Action = "upload"      // nur lokal
+	ActionPull       Action = "pull"        // nur remote, --pull
+	ActionRemoteOnly Action = "remote-only" // nur remote, ohne --pull
+	ActionInSync     Action = "in-sync"
+	ActionConflict   Action = "conflict" // beide Seiten, sha256 unterschiedlich (wird nie überschrieben)
+	ActionBlocked    Action = "blocked"  // nur lokal, Prüfung vor dem Upload fehlgeschlagen (zählt als Fehler)
+)
+
+type Entry struct {
+	Name   string `json:"name"`
+	Action Action `json:"action"`
+	Detail string `json:"detail,omitempty"`
+
+	LocalPath   string `json:"local_path,omitempty"`
+	LocalSha256 string `json:"local_sha256,omitempty"`
+	LocalSize   int64  `json:"local_size,omitempty"`
+
+	RemoteURL    string `json:"remote_url,omitempty"`
+	RemoteSha256 string `json:"remote_sha256,omitempty"`
+	RemoteSize   int64  `json:"remote_size,omitempty"`
+}
+
+// Size: zu übertragende Bytes
+func (e Entry) Size() int64 {
+	if e.Action == ActionPull {
+		return e.RemoteSize
+	}
+	return e.LocalSize
+}
+
+type Options struct {
+	Workdir string
+	Remote  []nexus.Asset
+	// URL für den Upload einer Datei (Nexus base + Name)
+	URLFor func(name string) string
+	Tag    string // leer = alle Tags
+	Pull   bool
+}
+
+// Compare stellt Bottles und Reports in workdir den Nexus-Assets gegenüber (Vergleich per sha256).
+func Compare(opts Options) ([]Entry, error) {
+	byName := map[string]*Entry{}
+
+	matches, err := filepath.Glob(filepath.Join(opts.Workdir, "*.bottle.*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range matches {
+		name := filepath.Base(p)
+		if !wanted(name, opts.Tag) {
+			continue
+		}
+		fi, err := os.Stat(p)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		sum, err := hash.FileSHA256(p)
+		if err != nil {
+			return nil, err
+		}
+		byName[name] = &Entry{Name: name, LocalPath: p, LocalSha256: sum, LocalSize: fi.Size(), RemoteURL: opts.URLFor(name)}
+	}
+
+	remote := map[string]bool{}
+	for _, a := range opts.Remote {
+		// nur Dateien direkt unter dem Prefix (so lädt auch upload hoch)
+		if strings.Contains(a.Path, "/") || !wanted(a.Name(), opts.Tag) {
+			continue
+		}
+		e := byName[a.Name()]
+		if e == nil {
+			e = &Entry{Name: a.Name(), LocalPath: filepath.Join(opts.Workdir, a.Name())}
+			byName[a.Name()] = e
+		}
+		e.RemoteURL, e.RemoteSha256, e.RemoteSize = a.DownloadURL, a.Sha256, a.Size
+		remote[a.Name()] = true
+	}
+
+	out := make([]Entry, 0, len(byName))
+	for _, e := range byName {
+		switch {
+		case e.LocalSha256 == "" && opts.Pull:
+			e.Action = ActionPull
+		case e.LocalSha256 == "":
+			e.Action = ActionRemoteOnly
+		case !remote[e.Name]:
+			e.Action = ActionUpload
+		case e.RemoteSha256 == "":
+			e.Action, e.Detail = ActionConflict, "remote checksum unknown"
+		case e.RemoteSha256 == e.LocalSha256:
+			e.Action = ActionInSync
+		default:
+			e.Action, e.Detail = ActionConflict, "sha256 differs"
+		}
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func wanted(name, tag string) bool {
+	p, ok := naming.Parse(name)
+	return ok && (tag == "" || p.Tag == tag)
+}
+
+// Transfer führt Uploads/Pulls aus.
+type Transfer struct {
+	Jobs int // gleichzeitige Übertragungen (default 4)
+	Put  func(ctx context.Context, url, path string) error
+	Get  func(ctx context.Context, url, path string) error
+}
+
+type Failure struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+type Summary struct {
+	Uploaded  int       `json:"uploaded"`
+	Pulled    int       `json:"pulled"`
+	InSync    int       `json:"in_sync"`
+	Conflicts int       `json:"conflicts"`
+	Remote    int       `json:"remote_only"`
+	Bytes     int64     `json:"bytes"`
+	Failed    []Failure `json:"failed,omitempty"`
+}
+
+// Count: Summary ohne Übertragung (dry-run: geplante Uploads/Pulls)
+func Count(entries []Entry) Summary {
+	var s Summary
+	for _, e := range entries {
+		switch e.Action {
+		case ActionUpload:
+			s.Uploaded++
+			s.Bytes += e.Size()
+		case ActionPull:
+			s.Pulled++
+			s.Bytes += e.Size()
+		case ActionInSync:
+			s.InSync++
+		case ActionConflict:
+			s.Conflicts++
+		case ActionRemoteOnly:
+			s.Remote++
+		case ActionBlocked:
+			s.Failed = append(s.Failed, Failure{Name: e.Name, Error: e.Detail})
+		}
+	}
+	return s
+}
+
+// Run überträgt alle upload/pull Einträge mit höchstens Jobs gleichzeitig.
+func (t Transfer) Run(ctx context.Context, entries []Entry, progress func(Entry, error)) Summary {
+	s := Count(entries)
+	s.Uploaded, s.Pulled, s.Bytes = 0, 0, 0
+	jobs := t.Jobs
+	if jobs <= 0 {
+		jobs = 4
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, jobs)
+	)
+	for _, e := range entries {
+		if e.Action != ActionUpload && e.Action != ActionPull {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			s.Failed = append(s.Failed, Failure{Name: e.Name, Error: ctx.Err().Error()})
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(e Entry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := t.one(ctx, e)
+			mu.Lock()
+			defer mu.Unlock()
+			if progress != nil {
+				progress(e, err)
+			}
+			if err != nil {
+				s.Failed = append(s.Failed, Failure{Name: e.Name, Error: err.Error()})
+				return
+			}
+			s.Bytes += e.Size()
+			if e.Action == ActionPull {
+				s.Pulled++
+			} else {
+				s.Uploaded++
+			}
+		}(e)
+	}
+	wg.Wait()
+	sort.Slice(s.Failed, func(i, j int) bool { return s.Failed[i].Name < s.Failed[j].Name })
+	return s
+}
+
+func (t Transfer) one(ctx context.Context, e Entry) error {
+	if e.Action == ActionUpload {
+		return t.Put(ctx, e.RemoteURL, e.LocalPath)
+	}
+	if err := t.Get(ctx, e.RemoteURL, e.LocalPath); err != nil {
+		return err
+	}
+	if e.RemoteSha256 == "" {
+		return nil
+	}
+	sum, err := hash.FileSHA256(e.LocalPath)
+	if err != nil {
+		return err
+	}
+	if sum != e.RemoteSha256 {
+		_ = os.Remove(e.LocalPath)
+		return fmt.Errorf("sha256 mismatch after download: got %s, nexus says %s", sum, e.RemoteSha256)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// File: eine Datei für den Nexus-Upload
+type File struct {
+	Path     string
+	URL      string
+	Kind     string // Metrik-Label (bottle, json, sidecar)
+	Required bool   // false: fehlt die Datei, wird sie still übersprungen
+}
+
+// Batch: Dateien eines Refs. Primary (das Bottle) geht zuerst hoch, Files erst nach dessen
+// Erfolg – ein Report soll nie auf ein Bottle zeigen, das nicht in Nexus liegt.
+type Batch struct {
+	Name    string
+	Primary *File // optional
+	Files   []File
+}
+
+// Error: fehlgeschlagene (oder wegen Abbruch nicht hochgeladene) Datei
+type Error struct {
+	Batch string
+	Path  string
+	URL   string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", filepath.Base(e.Path), e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Errors: alle Fehler eines Runs, sortiert nach Batch und Datei
+type Errors []*Error
+
+func (es Errors) Error() string {
+	if len(es) == 1 {
+		return es[0].Error()
+	}
+	parts := make([]string, len(es))
+	for i, e := range es {
+		parts[i] = e.Error()
+	}
+	return fmt.Sprintf("%d uploads failed: %s", len(es), strings.Join(parts, "; "))
+}
+
+func (es Errors) Unwrap() []error {
+	out := make([]error, len(es))
+	for i, e := range es {
+		out[i] = e
+	}
+	return out
+}
+
+// Pool lädt die Dateien mehrerer Batches mit höchstens Jobs gleichzeitigen Uploads hoch.
+type Pool struct {
+	Jobs int // default 4
+	Put  func(ctx context.Context, f File) error
+	// Done wird nach jeder Datei aufgerufen (nie gleichzeitig), err != nil bei Fehler
+	Done func(b *Batch, f File, err error)
+}
+
+// Run kehrt erst zurück, wenn alle gestarteten Uploads beendet sind. Nach Abbruch von ctx
+// startet nichts Neues mehr; nicht gestartete Dateien erscheinen mit ctx.Err() in Errors.
+func (p Pool) Run(ctx context.Context, batches []Batch) Errors {
+	jobs := p.Jobs
+	if jobs <= 0 {
+		jobs = 4
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs Errors
+		sem  = make(chan struct{}, jobs)
+	)
+	finish := func(b *Batch, f File, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs = append(errs, &Error{Batch: b.Name, Path: f.Path, URL: f.URL, Err: err})
+		}
+		if p.Done != nil {
+			p.Done(b, f, err)
+		}
+	}
+	// one: eine Datei; false = nicht hochgeladen (Fehler oder übersprungen)
+	one := func(b *Batch, f File) bool {
+		if _, err := os.Stat(f.Path); err != nil {
+			if !f.Required {
+				return true
+			}
+			finish(b, f, fmt.Errorf("file not found: %w", err))
+			return false
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			finish(b, f, fmt.Errorf("not started: %w", ctx.Err()))
+			return false
+		}
+		defer func() { <-sem }()
+		if err := ctx.Err(); err != nil {
+			finish(b, f, fmt.Errorf("not started: %w", err))
+			return false
+		}
+		err := p.Put(ctx, f)
+		finish(b, f, err)
+		return err == nil
+	}
+
+	for i := range batches {
+		b := &batches[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b.Primary != nil && !one(b, *b.Primary) {
+				for _, f := range b.Files {
+					if _, err := os.Stat(f.Path); err != nil && !f.Required {
+						continue
+					}
+					finish(b, f, fmt.Errorf("not uploaded: %s failed", filepath.Base(b.Primary.Path)))
+				}
+				return
+			}
+			var fw sync.WaitGroup
+			for _, f := range b.Files {
+				fw.Add(1)
+				go func() {
+					defer fw.Done()
+					one(b, f)
+				}()
+			}
+			fw.Wait()
+		}()
+	}
+	wg.Wait()
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Batch != errs[j].Batch {
+			return errs[i].Batch < errs[j].Batch
+		}
+		return errs[i].Path < errs[j].Path
+	})
+	return errs
+}
@@ -0,0 +1,203 @@
+package validate
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"gov-brew-bottle-creation/internal/bottle"
+	"gov-brew-bottle-creation/internal/report"
+)
+
+// Regeln
+const (
+	RuleFormat  = "format"  // kein lesbares tar.gz
+	RuleLayout  = "layout"  // Top-Level muss <formula>/<version> sein
+	RulePath    = "path"    // absolute oder ".." Pfade
+	RuleLink    = "link"    // Symlink zeigt aus dem Keg hinaus
+	RuleSetuid  = "setuid"  // setuid/setgid Bits
+	RuleDevice  = "device"  // Device Nodes, FIFOs
+	RuleReceipt = "receipt" // INSTALL_RECEIPT.json fehlt oder passt nicht
+	RuleSize    = "size"    // Grenzen aus Options
+)
+
+// maxPerRule: danach nur noch eine Sammelmeldung pro Regel
+const maxPerRule = 20
+
+type Options struct {
+	Formula string
+	Version string // stable Version aus dem Plan; Verzeichnis darf <version>_<revision> sein
+
+	MinSize     int64 // .tar.gz, 0 = keine Grenze
+	MaxSize     int64 // .tar.gz, 0 = keine Grenze
+	MaxUnpacked int64 // Summe der Dateigrößen, 0 = keine Grenze
+}
+
+type checker struct {
+	opts     Options
+	findings []report.Finding
+	perRule  map[string]int
+	layout   map[string]bool // bereits gemeldete fremde Top-Level Pfade
+}
+
+func (c *checker) add(rule string, sev report.Severity, p, format string, args ...any) {
+	c.perRule[rule]++
+	switch n := c.perRule[rule]; {
+	case n <= maxPerRule:
+		c.findings = append(c.findings, report.Finding{Rule: rule, Severity: sev, Path: p, Message: fmt.Sprintf(format, args...)})
+	case n == maxPerRule+1:
+		c.findings = append(c.findings, report.Finding{Rule: rule, Severity: sev, Message: "further findings of this rule omitted"})
+	}
+}
+
+// Bottle streamt das .tar.gz einmal und prüft alle Regeln. Ein nicht lesbares Archiv ist
+// selbst ein Finding (format); err nur, wenn die Datei nicht geöffnet werden kann.
+func Bottle(bottlePath string, opts Options) ([]report.Finding, error) {
+	f, err := os.Open(bottlePath)
+	if err != nil {
+		return nil, fmt.Errorf("open bottle: %w", err)
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat bottle: %w", err)
+	}
+
+	c := &checker{opts: opts, perRule: map[string]int{}, layout: map[string]bool{}}
+
+	if opts.MinSize > 0 && st.Size() < opts.MinSize {
+		c.add(RuleSize, report.SeverityError, "", "bottle is %d bytes, minimum is %d", st.Size(), opts.MinSize)
+	}
+	if opts.MaxSize > 0 && st.Size() > opts.MaxSize {
+		c.add(RuleSize, report.SeverityError, "", "bottle is %d bytes, maximum is %d", st.Size(), opts.MaxSize)
+	}
+
+	var (
+		unpacked int64
+		keg      string // <formula>/<version> aus dem ersten passenden Eintrag
+		receipt  []byte
+	)
+	werr := bottle.WalkReader(f, func(hdr *tar.Header, r io.Reader) error {
+		name := hdr.Name
+		if strings.HasPrefix(name, "/") {
+			c.add(RulePath, report.SeverityError, name, "absolute path")
+		}
+		if hasDotDot(name) {
+			c.add(RulePath, report.SeverityError, name, `path contains ".."`)
+		}
+
+		clean := strings.TrimPrefix(path.Clean("/"+name), "/")
+		top := topLevel(clean)
+		outside := top != "" && !c.kegOK(top)
+		if top == "" && clean != "" && clean != opts.Formula {
+			outside, top = true, clean // Datei/Verzeichnis direkt im Root
+		}
+		if outside && !c.layout[top] {
+			c.layout[top] = true
+			c.add(RuleLayout, report.SeverityError, name, "outside %s/%s", opts.Formula, opts.Version)
+		}
+		if keg == "" && c.kegOK(top) {
+			keg = top
+		}
+
+		if hdr.Mode&(04000|02000) != 0 {
+			c.add(RuleSetuid, report.SeverityError, name, "setuid/setgid bit set (mode %o)", hdr.Mode)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			c.add(RuleDevice, report.SeverityError, name, "device node or fifo")
+		case tar.TypeLink:
+			if strings.HasPrefix(hdr.Linkname, "/") || hasDotDot(hdr.Linkname) {
+				c.add(RulePath, report.SeverityError, name, "hard link to %q", hdr.Linkname)
+			}
+		case tar.TypeSymlink:
+			switch {
+			case strings.HasPrefix(hdr.Linkname, "/"):
+				c.add(RuleLink, report.SeverityWarning, name, "absolute symlink to %q", hdr.Linkname)
+			case top != "" && escapes(clean, hdr.Linkname, top):
+				c.add(RuleLink, report.SeverityWarning, name, "symlink %q points outside the keg", hdr.Linkname)
+			}
+		case tar.TypeReg:
+			unpacked += hdr.Size
+			if bottle.StripPrefix(name) == "INSTALL_RECEIPT.json" && c.kegOK(top) {
+				b, err := io.ReadAll(io.LimitReader(r, 4<<20))
+				if err != nil {
+					return err
+				}
+				receipt = b
+			}
+		}
+		return nil
+	})
+	if werr != nil {
+		c.add(RuleFormat, report.SeverityError, "", "%v", werr)
+		return c.findings, nil
+	}
+
+	if keg == "" {
+		c.add(RuleLayout, report.SeverityError, "", "no %s/%s directory in bottle", opts.Formula, opts.Version)
+	}
+	if opts.MaxUnpacked > 0 && unpacked > opts.MaxUnpacked {
+		c.add(RuleSize, report.SeverityError, "", "unpacked size is %d bytes, maximum is %d", unpacked, opts.MaxUnpacked)
+	}
+	c.checkReceipt(keg, receipt)
+	return c.findings, nil
+}
+
+func (c *checker) checkReceipt(keg string, raw []byte) {
+	if raw == nil {
+		c.add(RuleReceipt, report.SeverityError, "", "INSTALL_RECEIPT.json missing")
+		return
+	}
+	p := keg + "/INSTALL_RECEIPT.json"
+	var rc bottle.Receipt
+	if err := json.Unmarshal(raw, &rc); err != nil {
+		c.add(RuleReceipt, report.SeverityError, p, "parse: %v", err)
+		return
+	}
+	if v := rc.Source.Versions.Stable; v != c.opts.Version {
+		c.add(RuleReceipt, report.SeverityError, p, "receipt version %q does not match %q", v, c.opts.Version)
+	}
+}
+
+// kegOK: top ist <formula>/<version> oder <formula>/<version>_<revision>
+func (c *checker) kegOK(top string) bool {
+	formula, dir, ok := strings.Cut(top, "/")
+	if !ok || formula != c.opts.Formula {
+		return false
+	}
+	if dir == c.opts.Version {
+		return true
+	}
+	rev, ok := strings.CutPrefix(dir, c.opts.Version+"_")
+	return ok && rev != "" && strings.Trim(rev, "0123456789") == ""
+}
+
+// topLevel: die ersten beiden Pfadteile; "" für die Top-Level Verzeichnisse selbst
+func topLevel(clean string) string {
+	parts := strings.SplitN(clean, "/", 3)
+	if len(parts) < 2 || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+func hasDotDot(p string) bool {
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// escapes: relativer Symlink name -> target landet außerhalb von keg
+func escapes(name, target, keg string) bool {
+	resolved := path.Join(path.Dir(name), target)
+	return resolved != keg && !strings.HasPrefix(resolved, keg+"/")
+}
@@ -0,0 +1,189 @@
+package validate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	"gov-brew-bottle-creation/internal/report"
+)
+
+type entry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+	mode     int64
+}
+
+// writeBottle: tar.gz im Speicher bauen und als Datei ablegen (Bottle liest einen Pfad)
+func writeBottle(t *testing.T, entries []entry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: e.mode}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0o644
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(e.body))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "gov-srt--1.5.4.arm64_sonoma.bottle.tar.gz")
+	if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+const receipt154 = `{"source":{"versions":{"stable":"1.5.4"}}}`
+
+func TestBottle(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		want    []string // Regeln der Findings, sortiert; "rule:warn" für Warnungen
+	}{
+		{
+			name: "valid",
+			entries: []entry{
+				{name: "gov-srt/1.5.4/", typeflag: tar.TypeDir, mode: 0o755},
+				{name: "gov-srt/1.5.4/INSTALL_RECEIPT.json", body: receipt154},
+				{name: "gov-srt/1.5.4/lib/libsrt.1.5.dylib", body: "lib"},
+				{name: "gov-srt/1.5.4/lib/libsrt.dylib", typeflag: tar.TypeSymlink, linkname: "libsrt.1.5.dylib"},
+			},
+		},
+		{
+			name: "keg with revision",
+			entries: []entry{
+				{name: "gov-srt/1.5.4_1/INSTALL_RECEIPT.json", body: receipt154},
+				{name: "gov-srt/1.5.4_1/bin/srt-live-transmit", body: "bin", mode: 0o755},
+			},
+		},
+		{
+			name: "keg with bad revision suffix",
+			entries: []entry{
+				{name: "gov-srt/1.5.4_rc1/INSTALL_RECEIPT.json", body: receipt154},
+			},
+			want: []string{"layout", "layout", "receipt"},
+		},
+		{
+			name: "symlink escapes the keg",
+			entries: []entry{
+				{name: "gov-srt/1.5.4/INSTALL_RECEIPT.json", body: receipt154},
+				{name: "gov-srt/1.5.4/lib/libssl.dylib", typeflag: tar.TypeSymlink, linkname: "../../../gov-openssl@3/3.4.0/lib/libssl.dylib"},
+				{name: "gov-srt/1.5.4/etc/ca.pem", typeflag: tar.TypeSymlink, linkname: "/etc/ssl/cert.pem"},
+			},
+			want: []string{"link:warn", "link:warn"},
+		},
+		{
+			name: "symlink up to the keg root stays inside",
+			entries: []entry{
+				{name: "gov-srt/1.5.4/INSTALL_RECEIPT.json", body: receipt154},
+				{name: "gov-srt/1.5.4/lib/current", typeflag: tar.TypeSymlink, linkname: ".."},
+			},
+		},
+		{
+			name: "receipt for another version",
+			entries: []entry{
+				{name: "gov-srt/1.5.4/INSTALL_RECEIPT.json", body: `{"source":{"versions":{"stable":"1.5.3"}}}`},
+			},
+			want: []string{"receipt"},
+		},
+		{
+			name: "receipt missing",
+			entries: []entry{
+				{name: "gov-srt/1.5.4/bin/srt-live-transmit", body: "bin"},
+			},
+			want: []string{"receipt"},
+		},
+		{
+			name: "receipt not json",
+			entries: []entry{
+				{name: "gov-srt/1.5.4/INSTALL_RECEIPT.json", body: "{"},
+			},
+			want: []string{"receipt"},
+		},
+		{
+			name: "path, setuid and device",
+			entries: []entry{
+				{name: "gov-srt/1.5.4/INSTALL_RECEIPT.json", body: receipt154},
+				{name: "gov-srt/1.5.4/../../evil", body: "x"},
+				{name: "gov-srt/1.5.4/bin/su", body: "x", mode: 0o4755},
+				{name: "gov-srt/1.5.4/dev/null", typeflag: tar.TypeChar},
+			},
+			want: []string{"device", "layout", "path", "setuid"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := writeBottle(t, tt.entries)
+			findings, err := Bottle(p, Options{Formula: "gov-srt", Version: "1.5.4"})
+			if err != nil {
+				t.Fatalf("Bottle: %v", err)
+			}
+			var got []string
+			for _, f := range findings {
+				r := f.Rule
+				if f.Severity == report.SeverityWarning {
+					r += ":warn"
+				}
+				got = append(got, r)
+			}
+			sort.Strings(got)
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("findings = %v, want %v\n%+v", got, tt.want, findings)
+			}
+		})
+	}
+}
+
+func TestBottleSizeAndFormat(t *testing.T) {
+	p := writeBottle(t, []entry{
+		{name: "gov-srt/1.5.4/INSTALL_RECEIPT.json", body: receipt154},
+		{name: "gov-srt/1.5.4/lib/big", body: string(make([]byte, 4096))},
+	})
+	findings, err := Bottle(p, Options{Formula: "gov-srt", Version: "1.5.4", MinSize: 1 << 20, MaxUnpacked: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := report.Errors(findings); n != 2 {
+		t.Fatalf("%d errors, want min size and unpacked size: %+v", n, findings)
+	}
+
+	bad := filepath.Join(t.TempDir(), "broken.bottle.tar.gz")
+	if err := os.WriteFile(bad, []byte("not gzip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	findings, err = Bottle(bad, Options{Formula: "gov-srt", Version: "1.5.4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Rule != RuleFormat {
+		t.Fatalf("findings = %+v, want one format error", findings)
+	}
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"gov-brew-bottle-creation/internal/gitutil"
+	"gov-brew-bottle-creation/internal/service"
+)
+
+// Handler nimmt Push-Webhooks entgegen und reiht Bottle-Builds für geänderte Formula/*.rb ein.
+type Handler struct {
+	Secret     string
+	TapWorkdir string // lokaler Clone des Taps (wird vor dem Diff gefetcht)
+	Tap        string // owner/tap; leer = aus dem Repository-Namen ableiten
+	Branch     string // nur Pushes auf diesen Branch; leer = alle
+	Steps      []string
+	Submit     func(service.JobRequest) (service.Job, error)
+
+	mu sync.Mutex // ein fetch/diff gleichzeitig im Tap-Clone
+}
+
+type response struct {
+	Message string   `json:"message"`
+	JobID   string   `json:"job_id,omitempty"`
+	Refs    []string `json:"refs,omitempty"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 25<<20))
+	if err != nil {
+		reply(w, http.StatusBadRequest, response{Message: err.Error()})
+		return
+	}
+	if err := verify(r.Header, body, h.Secret); err != nil {
+		reply(w, http.StatusUnauthorized, response{Message: err.Error()})
+		return
+	}
+
+	switch ev := event(r.Header); ev {
+	case "push":
+	case "ping":
+		reply(w, http.StatusOK, response{Message: "pong"})
+		return
+	default:
+		reply(w, http.StatusAccepted, response{Message: fmt.Sprintf("ignored event %q", ev)})
+		return
+	}
+
+	var p push
+	if err := json.Unmarshal(body, &p); err != nil {
+		reply(w, http.StatusBadRequest, response{Message: "parse payload: " + err.Error()})
+		return
+	}
+	if p.After == "" || p.After == gitutil.ZeroSHA {
+		reply(w, http.StatusAccepted, response{Message: "branch deleted, nothing to build"})
+		return
+	}
+	// landen als Revisionen in git diff: nur volle SHAs
+	if !gitutil.IsSHA(p.After) || (p.Before != "" && !gitutil.IsSHA(p.Before)) {
+		reply(w, http.StatusBadRequest, response{Message: "before/after must be full commit SHAs"})
+		return
+	}
+	if h.Branch != "" && p.Ref != "refs/heads/"+h.Branch {
+		reply(w, http.StatusAccepted, response{Message: fmt.Sprintf("ignored push to %s", p.Ref)})
+		return
+	}
+
+	tap := h.Tap
+	if tap == "" {
+		tap = tapFromRepo(p.repoName())
+	}
+	if tap == "" {
+		reply(w, http.StatusBadRequest, response{Message: "cannot determine tap name from payload"})
+		return
+	}
+
+	names, err := h.changedFormulae(r.Context(), p.Before, p.After)
+	if err != nil {
+		reply(w, http.StatusBadGateway, response{Message: err.Error()})
+		return
+	}
+	if len(names) == 0 {
+		reply(w, http.StatusOK, response{Message: "no formula changes"})
+		return
+	}
+
+	refs := make([]string, 0, len(names))
+	for _, n := range names {
+		refs = append(refs, tap+"/"+n)
+	}
+	j, err := h.Submit(service.JobRequest{Refs: refs, Steps: h.Steps, Source: "webhook " + p.After[:min(12, len(p.After))]})
+	if err != nil {
+		reply(w, http.StatusInternalServerError, response{Message: err.Error()})
+		return
+	}
+	reply(w, http.StatusAccepted, response{Message: "enqueued", JobID: j.ID, Refs: refs})
+}
+
+// changedFormulae: fetch im Tap-Clone, dann Formula/**/*.rb zwischen before und after.
+func (h *Handler) changedFormulae(ctx context.Context, before, after string) ([]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := gitutil.Git(ctx, h.TapWorkdir, "fetch", "--quiet", "origin"); err != nil {
+		return nil, err
+	}
+	files, err := gitutil.ChangedFiles(ctx, h.TapWorkdir, before, after, "Formula")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var names []string
+	for _, f := range files {
+		if !strings.HasPrefix(f, "Formula/") || path.Ext(f) != ".rb" {
+			continue
+		}
+		n := strings.TrimSuffix(path.Base(f), ".rb")
+		if !seen[n] {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+	return names, nil
+}
+
+// tapFromRepo: tlchmi/homebrew-ch-gov-brew -> tlchmi/ch-gov-brew
+func tapFromRepo(full string) string {
+	owner, repo, ok := strings.Cut(full, "/")
+	if !ok || owner == "" || repo == "" {
+		return ""
+	}
+	if i := strings.LastIndexByte(repo, '/'); i >= 0 { // GitLab Subgroups
+		repo = repo[i+1:]
+	}
+	return strings.ToLower(owner) + "/" + strings.TrimPrefix(strings.ToLower(repo), "homebrew-")
+}
+
+func reply(w http.ResponseWriter, status int, v response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
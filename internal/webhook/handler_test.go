@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gov-brew-bottle-creation/internal/service"
+)
+
+func TestHandlerRejects(t *testing.T) {
+	const secret = "s3cret"
+	sha := strings.Repeat("a", 40)
+
+	tests := []struct {
+		name   string
+		body   string
+		signed bool
+		want   int
+	}{
+		{"unsigned", `{"ref":"refs/heads/main","before":"` + sha + `","after":"` + sha + `"}`, false, http.StatusUnauthorized},
+		{"option as after", `{"ref":"refs/heads/main","before":"` + sha + `","after":"--output=/tmp/x"}`, true, http.StatusBadRequest},
+		{"option as before", `{"ref":"refs/heads/main","before":"--no-index","after":"` + sha + `"}`, true, http.StatusBadRequest},
+		{"short sha", `{"ref":"refs/heads/main","before":"` + sha + `","after":"abc123"}`, true, http.StatusBadRequest},
+		{"branch deleted", `{"ref":"refs/heads/main","before":"` + sha + `","after":"` + strings.Repeat("0", 40) + `"}`, true, http.StatusAccepted},
+		{"other branch", `{"ref":"refs/heads/dev","before":"` + sha + `","after":"` + sha + `"}`, true, http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				Secret: secret,
+				Branch: "main",
+				Submit: func(service.JobRequest) (service.Job, error) {
+					t.Fatal("Submit must not be called")
+					return service.Job{}, nil
+				},
+			}
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			req.Header.Set("X-GitHub-Event", "push")
+			if tt.signed {
+				req.Header.Set("X-Hub-Signature-256", "sha256="+sign(secret, []byte(tt.body)))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// push: gemeinsame Felder der GitHub/Gitea/GitLab Push-Payloads
+type push struct {
+	Ref    string `json:"ref"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+
+	Repository struct {
+		FullName string `json:"full_name"` // GitHub, Gitea
+	} `json:"repository"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"` // GitLab
+	} `json:"project"`
+}
+
+func (p push) repoName() string {
+	if p.Repository.FullName != "" {
+		return p.Repository.FullName
+	}
+	return p.Project.PathWithNamespace
+}
+
+var (
+	errNoSignature  = errors.New("missing webhook signature")
+	errBadSignature = errors.New("invalid webhook signature")
+)
+
+// provider erkennt die Quelle am Event-Header.
+func provider(h http.Header) string {
+	switch {
+	case h.Get("X-Gitea-Event") != "":
+		return "gitea"
+	case h.Get("X-Gitlab-Event") != "":
+		return "gitlab"
+	case h.Get("X-GitHub-Event") != "":
+		return "github"
+	}
+	return ""
+}
+
+// event normalisiert den Event-Typ ("push", "ping", ...).
+func event(h http.Header) string {
+	switch provider(h) {
+	case "gitea":
+		return h.Get("X-Gitea-Event")
+	case "gitlab":
+		if h.Get("X-Gitlab-Event") == "Push Hook" {
+			return "push"
+		}
+		return h.Get("X-Gitlab-Event")
+	case "github":
+		return h.Get("X-GitHub-Event")
+	}
+	return ""
+}
+
+// verify prüft die Signatur:
+//   - GitHub: X-Hub-Signature-256: sha256=<hmac>
+//   - Gitea:  X-Gitea-Signature: <hmac> (sendet zusätzlich X-Hub-Signature-256)
+//   - GitLab: X-Gitlab-Token: <secret> (GitLab signiert nicht, das Token wird verglichen)
+func verify(h http.Header, body []byte, secret string) error {
+	if tok := h.Get("X-Gitlab-Token"); tok != "" {
+		if subtle.ConstantTimeCompare([]byte(tok), []byte(secret)) != 1 {
+			return errBadSignature
+		}
+		return nil
+	}
+
+	sig := strings.TrimPrefix(h.Get("X-Hub-Signature-256"), "sha256=")
+	if sig == "" {
+		sig = h.Get("X-Gitea-Signature")
+	}
+	if sig == "" {
+		return errNoSignature
+	}
+
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return errBadSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return errBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "s3cret"
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    error
+	}{
+		{"github ok", map[string]string{"X-Hub-Signature-256": "sha256=" + sign(secret, body)}, nil},
+		{"github wrong secret", map[string]string{"X-Hub-Signature-256": "sha256=" + sign("other", body)}, errBadSignature},
+		{"github body changed", map[string]string{"X-Hub-Signature-256": "sha256=" + sign(secret, append(body, ' '))}, errBadSignature},
+		{"github not hex", map[string]string{"X-Hub-Signature-256": "sha256=zz"}, errBadSignature},
+		{"gitea ok", map[string]string{"X-Gitea-Signature": sign(secret, body)}, nil},
+		{"gitea wrong", map[string]string{"X-Gitea-Signature": sign("other", body)}, errBadSignature},
+		{"gitlab ok", map[string]string{"X-Gitlab-Token": secret}, nil},
+		{"gitlab wrong", map[string]string{"X-Gitlab-Token": "nope"}, errBadSignature},
+		{"missing", nil, errNoSignature},
+		{"empty prefix only", map[string]string{"X-Hub-Signature-256": "sha256="}, errNoSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+			if got := verify(h, body, secret); got != tt.want {
+				t.Fatalf("verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvent(t *testing.T) {
+	tests := []struct {
+		header, value, want string
+	}{
+		{"X-GitHub-Event", "push", "push"},
+		{"X-GitHub-Event", "ping", "ping"},
+		{"X-Gitea-Event", "push", "push"},
+		{"X-Gitlab-Event", "Push Hook", "push"},
+		{"X-Gitlab-Event", "Tag Push Hook", "Tag Push Hook"},
+		{"X-Other", "push", ""},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		h.Set(tt.header, tt.value)
+		if got := event(h); got != tt.want {
+			t.Errorf("event(%s: %s) = %q, want %q", tt.header, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTapFromRepo(t *testing.T) {
+	tests := map[string]string{
+		"tlchmi/homebrew-ch-gov-brew":    "tlchmi/ch-gov-brew",
+		"TLCHMI/Homebrew-Tools":          "tlchmi/tools",
+		"group/sub/homebrew-ch-gov-brew": "group/ch-gov-brew",
+		"noslash":                        "",
+		"/repo":                          "",
+	}
+	for in, want := range tests {
+		if got := tapFromRepo(in); got != want {
+			t.Errorf("tapFromRepo(%q) = %q, want %q", in, got, want)
+		}
+	}
+}